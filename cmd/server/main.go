@@ -3,50 +3,118 @@ package main
 import (
 	"log"
 	"net/http"
+	"net/netip"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/jordanhimawan/payroll-mgmt/internal/config"
-	postgres "github.com/jordanhimawan/payroll-mgmt/internal/database"
+	database "github.com/jordanhimawan/payroll-mgmt/internal/database"
 	"github.com/jordanhimawan/payroll-mgmt/internal/handlers"
+	"github.com/jordanhimawan/payroll-mgmt/internal/jwtkeys"
+	appMiddleware "github.com/jordanhimawan/payroll-mgmt/internal/middleware"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository/postgres"
 	"github.com/jordanhimawan/payroll-mgmt/internal/services"
+	"github.com/jordanhimawan/payroll-mgmt/internal/storage"
 )
 
 func main() {
 	// Load configuration
 	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("Invalid configuration:", err)
+	}
+
+	timeZone, err := time.LoadLocation(cfg.TimeZone)
+	if err != nil {
+		log.Fatal("Invalid configuration:", err)
+	}
 
 	// Initialize database
-	db, err := postgres.NewPostgresConnection(cfg.DatabaseURL)
+	db, err := database.NewPostgresConnection(cfg.DatabaseURL)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 	defer db.Close()
 
+	// Initialize storage
+	receiptStorage, err := storage.NewLocalStorage(cfg.ReceiptStorageDir)
+	if err != nil {
+		log.Fatal("Failed to initialize receipt storage:", err)
+	}
+
+	// Load token signing keys
+	keys := jwtkeys.NewHMAC([]byte(cfg.JWTSecret))
+	if cfg.JWTKeysDir != "" {
+		keys, err = jwtkeys.LoadDir(cfg.JWTKeysDir, cfg.JWTSigningKeyID)
+		if err != nil {
+			log.Fatal("Failed to load JWT keys:", err)
+		}
+	}
+
 	// Initialize repositories
 	userRepo := postgres.NewUserRepository(db)
+	roleRepo := postgres.NewRoleRepository(db)
 	attendancePeriodRepo := postgres.NewAttendancePeriodRepository(db)
 	attendanceRepo := postgres.NewAttendanceRepository(db)
 	overtimeRepo := postgres.NewOvertimeRepository(db)
 	reimbursementRepo := postgres.NewReimbursementRepository(db)
+	payslipRepo := postgres.NewPayslipRepository(db)
+	calendarRepo := postgres.NewCalendarRepository(db)
+	leaveRepo := postgres.NewLeaveRepository(db)
+	salaryRepo := postgres.NewSalaryRepository(db)
+	exchangeRateRepo := postgres.NewExchangeRateRepository(db)
+	tokenRepo := postgres.NewTokenRepository(db)
+	loginRepo := postgres.NewLoginRepository(db)
+	mfaRepo := postgres.NewMFARepository(db)
+	passwordRepo := postgres.NewPasswordRepository(db)
+	auditRepo := postgres.NewAuditRepository(db)
 
 	// Initialize services
-	authService := services.NewAuthService(userRepo, cfg.JWTSecret)
-	attendanceService := services.NewAttendanceService(attendanceRepo, attendancePeriodRepo)
-	payrollService := services.NewPayrollService(userRepo, attendanceRepo, overtimeRepo, reimbursementRepo)
+	passwordPolicy, err := services.NewPasswordPolicy(passwordRepo, cfg.PasswordMinLength, cfg.PasswordHistorySize, cfg.BreachedPasswordsFile)
+	if err != nil {
+		log.Fatal("Failed to initialize password policy:", err)
+	}
+	authService, err := services.NewAuthService(userRepo, roleRepo, tokenRepo, loginRepo, mfaRepo, passwordRepo, passwordPolicy, keys, cfg.AccessTokenTTL, cfg.RefreshTokenTTL, cfg.MFAIssuer)
+	if err != nil {
+		log.Fatal("Failed to initialize auth service:", err)
+	}
+	calendarService := services.NewCalendarService(calendarRepo, attendancePeriodRepo, timeZone)
+	userService := services.NewUserService(userRepo, roleRepo, calendarRepo, calendarService, tokenRepo, loginRepo, mfaRepo, passwordRepo, passwordPolicy)
+	attendanceService := services.NewAttendanceService(attendanceRepo, attendancePeriodRepo, userRepo, calendarService)
+	overtimeService := services.NewOvertimeService(overtimeRepo, attendanceRepo, attendancePeriodRepo, userRepo, calendarService)
+	reimbursementService := services.NewReimbursementService(reimbursementRepo, attendancePeriodRepo, receiptStorage)
+	leaveService := services.NewLeaveService(leaveRepo, userRepo, attendancePeriodRepo, calendarService)
+	salaryService := services.NewSalaryService(salaryRepo, userRepo, roleRepo, attendancePeriodRepo)
+	exchangeRateService := services.NewExchangeRateService(exchangeRateRepo)
+	auditService := services.NewAuditService(auditRepo)
+	payrollService := services.NewPayrollService(userRepo, attendancePeriodRepo, attendanceRepo, overtimeRepo, reimbursementRepo, payslipRepo, leaveRepo, salaryRepo, exchangeRateRepo, calendarService)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
-	adminHandler := handlers.NewAdminHandler(attendancePeriodRepo)
-	employeeHandler := handlers.NewEmployeeHandler(attendanceService, overtimeRepo, reimbursementRepo)
+	adminHandler := handlers.NewAdminHandler(attendancePeriodRepo, payrollService, reimbursementService)
+	employeeHandler := handlers.NewEmployeeHandler(attendanceService, overtimeService, reimbursementService, payrollService)
+	managerHandler := handlers.NewManagerHandler(overtimeService)
+	receiptHandler := handlers.NewReceiptHandler(reimbursementService)
+	calendarHandler := handlers.NewCalendarHandler(calendarService)
+	leaveHandler := handlers.NewLeaveHandler(leaveService)
+	userHandler := handlers.NewUserHandler(userService)
+	salaryHandler := handlers.NewSalaryHandler(salaryService)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(exchangeRateService)
+	auditHandler := handlers.NewAuditHandler(auditService)
 	commonHandler := handlers.NewCommonHandler(attendancePeriodRepo)
 
 	// Initialize middleware
-	authMiddleware := apiMiddleware.NewAuthMiddleware(cfg.JWTSecret)
+	authMiddleware := appMiddleware.NewAuthMiddleware(authService)
+	trustedProxies, err := appMiddleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatal("Invalid configuration:", err)
+	}
 
 	// Setup routes
-	router := setupRoutes(authHandler, adminHandler, employeeHandler, commonHandler, authMiddleware)
+	router := setupRoutes(authHandler, adminHandler, employeeHandler, managerHandler, receiptHandler, calendarHandler, leaveHandler, userHandler, salaryHandler, exchangeRateHandler, auditHandler, commonHandler, authMiddleware, trustedProxies)
 
 	// Start server
 	log.Printf("Server starting on port %s", cfg.Port)
@@ -57,15 +125,26 @@ func setupRoutes(
 	authHandler *handlers.AuthHandler,
 	adminHandler *handlers.AdminHandler,
 	employeeHandler *handlers.EmployeeHandler,
+	managerHandler *handlers.ManagerHandler,
+	receiptHandler *handlers.ReceiptHandler,
+	calendarHandler *handlers.CalendarHandler,
+	leaveHandler *handlers.LeaveHandler,
+	userHandler *handlers.UserHandler,
+	salaryHandler *handlers.SalaryHandler,
+	exchangeRateHandler *handlers.ExchangeRateHandler,
+	auditHandler *handlers.AuditHandler,
 	commonHandler *handlers.CommonHandler,
 	authMiddleware *appMiddleware.AuthMiddleware,
+	trustedProxies []netip.Prefix,
 ) chi.Router {
 	r := chi.NewRouter()
 
 	// Global middleware
+	r.Use(appMiddleware.RealIP(trustedProxies))
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
+	r.Use(appMiddleware.AuditSource)
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
@@ -76,27 +155,142 @@ func setupRoutes(
 	}))
 
 	// Public routes
+	r.Get("/.well-known/jwks.json", authHandler.JWKS)
 	r.Post("/api/v1/auth/login", authHandler.Login)
+	r.Post("/api/v1/auth/refresh", authHandler.Refresh)
+	r.Post("/api/v1/auth/mfa/verify", authHandler.VerifyMFA)
+	r.Post("/api/v1/auth/mfa/setup", authHandler.SetupMFA)
+	r.Post("/api/v1/auth/mfa/activate", authHandler.ActivateMFA)
+	r.Post("/api/v1/auth/password/reset", authHandler.ResetPassword)
 
 	// Protected routes
 	r.Route("/api/v1", func(r chi.Router) {
 		r.Use(authMiddleware.Authenticate)
 
+		r.Post("/auth/logout", authHandler.Logout)
+		r.Post("/auth/password", authHandler.ChangePassword)
+		r.Post("/auth/mfa/enroll", authHandler.EnrollMFA)
+		r.Post("/auth/mfa/confirm", authHandler.ConfirmMFA)
+		r.Post("/auth/mfa/disable", authHandler.DisableMFA)
+		r.Post("/auth/mfa/recovery-codes", authHandler.RegenerateRecoveryCodes)
+
 		// Admin routes
 		r.Route("/admin", func(r chi.Router) {
-			r.Use(authMiddleware.RequireAdmin)
-			r.Post("/attendance-periods", adminHandler.CreateAttendancePeriod)
+			r.With(authMiddleware.RequirePermission(models.PermAttendancePeriodsManage)).
+				Post("/attendance-periods", adminHandler.CreateAttendancePeriod)
+			r.With(authMiddleware.RequirePermission(models.PermPayrollRun)).
+				Post("/attendance-periods/{periodID}/payroll", adminHandler.RunPayroll)
+			r.With(authMiddleware.RequirePermission(models.PermPayrollRead)).
+				Get("/attendance-periods/{periodID}/payroll-summary", adminHandler.GetPayrollSummary)
+			r.With(authMiddleware.RequirePermission(models.PermReimbursementsRead)).
+				Get("/reimbursements", adminHandler.ListReimbursements)
+			r.With(authMiddleware.RequirePermission(models.PermReimbursementsApprove)).
+				Post("/reimbursements/{reimbursementID}/approve", adminHandler.ApproveReimbursement)
+			r.With(authMiddleware.RequirePermission(models.PermReimbursementsApprove)).
+				Post("/reimbursements/{reimbursementID}/reject", adminHandler.RejectReimbursement)
+			r.With(authMiddleware.RequirePermission(models.PermUsersRead)).
+				Get("/users", userHandler.ListUsers)
+			r.With(authMiddleware.RequirePermission(models.PermUsersRead)).
+				Get("/users/{userID}", userHandler.GetUser)
+			r.With(authMiddleware.RequirePermission(models.PermUsersManage)).
+				Post("/users", userHandler.CreateUser)
+			r.With(authMiddleware.RequirePermission(models.PermUsersManage)).
+				Put("/users/{userID}", userHandler.UpdateUser)
+			r.With(authMiddleware.RequirePermission(models.PermUsersManage)).
+				Post("/users/{userID}/password", userHandler.SetPassword)
+			r.With(authMiddleware.RequirePermission(models.PermUsersManage)).
+				Post("/users/{userID}/password-reset", userHandler.IssuePasswordReset)
+			r.With(authMiddleware.RequirePermission(models.PermUsersManage)).
+				Post("/users/{userID}/deactivate", userHandler.Deactivate)
+			r.With(authMiddleware.RequirePermission(models.PermUsersManage)).
+				Post("/users/{userID}/reactivate", userHandler.Reactivate)
+			r.With(authMiddleware.RequirePermission(models.PermUsersManage)).
+				Post("/users/{userID}/unlock", userHandler.Unlock)
+			r.With(authMiddleware.RequirePermission(models.PermUsersManage)).
+				Post("/users/{userID}/mfa/reset", userHandler.ResetMFA)
+			r.With(authMiddleware.RequirePermission(models.PermUsersRead)).
+				Get("/users/{userID}/login-history", userHandler.GetLoginHistory)
+			r.With(authMiddleware.RequirePermission(models.PermUsersRead)).
+				Get("/users/{userID}/salaries", salaryHandler.GetHistory)
+			r.With(authMiddleware.RequirePermission(models.PermUsersManage)).
+				Post("/users/{userID}/salaries", salaryHandler.ScheduleChange)
+			r.With(authMiddleware.RequirePermission(models.PermUsersManage)).
+				Delete("/users/{userID}/salaries/{salaryID}", salaryHandler.CancelChange)
+			r.With(authMiddleware.RequirePermission(models.PermUsersRead)).
+				Get("/users/{userID}/leave-balances", leaveHandler.GetUserBalances)
+			r.With(authMiddleware.RequirePermission(models.PermUsersManage)).
+				Put("/users/{userID}/leave-entitlements/{leaveType}", leaveHandler.SetEntitlement)
+			r.With(authMiddleware.RequirePermission(models.PermAuditLogsRead)).
+				Get("/audit-logs", auditHandler.ListAuditLogs)
+
+			r.Group(func(r chi.Router) {
+				r.Use(authMiddleware.RequirePermission(models.PermCalendarManage))
+				r.Get("/locations", calendarHandler.ListLocations)
+				r.Post("/locations", calendarHandler.CreateLocation)
+				r.Put("/locations/{locationID}", calendarHandler.UpdateLocation)
+				r.Get("/holidays", calendarHandler.ListHolidays)
+				r.Post("/holidays", calendarHandler.CreateHoliday)
+				r.Post("/holidays/import", calendarHandler.ImportHolidays)
+				r.Put("/holidays/{holidayID}", calendarHandler.UpdateHoliday)
+				r.Delete("/holidays/{holidayID}", calendarHandler.DeleteHoliday)
+			})
+
+			r.Group(func(r chi.Router) {
+				r.Use(authMiddleware.RequirePermission(models.PermExchangeRatesManage))
+				r.Get("/exchange-rates", exchangeRateHandler.ListRates)
+				r.Post("/exchange-rates", exchangeRateHandler.CreateRate)
+				r.Put("/exchange-rates/{rateID}", exchangeRateHandler.UpdateRate)
+				r.Delete("/exchange-rates/{rateID}", exchangeRateHandler.DeleteRate)
+			})
 		})
 
 		// Employee routes
 		r.Route("/employee", func(r chi.Router) {
-			r.Post("/attendance", employeeHandler.SubmitAttendance)
-			r.Post("/overtime", employeeHandler.SubmitOvertime)
-			r.Post("/reimbursement", employeeHandler.SubmitReimbursement)
+			r.With(authMiddleware.RequirePermission(models.PermAttendanceSubmit)).
+				Post("/attendance/check-in", employeeHandler.CheckIn)
+			r.With(authMiddleware.RequirePermission(models.PermAttendanceSubmit)).
+				Post("/attendance/check-out", employeeHandler.CheckOut)
+			r.With(authMiddleware.RequirePermission(models.PermOvertimeSubmit)).
+				Post("/overtime", employeeHandler.SubmitOvertime)
+			r.With(authMiddleware.RequirePermission(models.PermReimbursementsSubmit)).
+				Post("/reimbursement", employeeHandler.SubmitReimbursement)
+			r.With(authMiddleware.RequirePermission(models.PermReimbursementsSubmit)).
+				Put("/reimbursements/{reimbursementID}", employeeHandler.UpdateReimbursement)
+			r.With(authMiddleware.RequirePermission(models.PermReimbursementsSubmit)).
+				Post("/reimbursements/{reimbursementID}/receipt", employeeHandler.UploadReceipt)
+			r.With(authMiddleware.RequirePermission(models.PermPayslipsReadOwn)).
+				Get("/payslips/{periodID}", employeeHandler.GetPayslip)
+			r.With(authMiddleware.RequirePermission(models.PermLeaveSubmit)).
+				Get("/leave/balances", leaveHandler.GetOwnBalances)
+			r.With(authMiddleware.RequirePermission(models.PermLeaveSubmit)).
+				Get("/leave-requests", leaveHandler.ListOwnRequests)
+			r.With(authMiddleware.RequirePermission(models.PermLeaveSubmit)).
+				Post("/leave-requests", leaveHandler.SubmitRequest)
+			r.With(authMiddleware.RequirePermission(models.PermLeaveSubmit)).
+				Post("/leave-requests/{leaveRequestID}/cancel", leaveHandler.CancelRequest)
+		})
+
+		// Manager routes
+		r.Route("/manager", func(r chi.Router) {
+			r.With(authMiddleware.RequirePermission(models.PermOvertimeApprove)).
+				Get("/overtime", managerHandler.GetPendingOvertime)
+			r.With(authMiddleware.RequirePermission(models.PermOvertimeApprove)).
+				Post("/overtime/{overtimeID}/approve", managerHandler.ApproveOvertime)
+			r.With(authMiddleware.RequirePermission(models.PermOvertimeApprove)).
+				Post("/overtime/{overtimeID}/reject", managerHandler.RejectOvertime)
+			r.With(authMiddleware.RequirePermission(models.PermLeaveApprove)).
+				Get("/leave-requests", leaveHandler.GetPendingRequests)
+			r.With(authMiddleware.RequirePermission(models.PermLeaveApprove)).
+				Post("/leave-requests/{leaveRequestID}/approve", leaveHandler.ApproveRequest)
+			r.With(authMiddleware.RequirePermission(models.PermLeaveApprove)).
+				Post("/leave-requests/{leaveRequestID}/reject", leaveHandler.RejectRequest)
 		})
 
 		// Common routes
-		r.Get("/attendance-periods", commonHandler.GetAttendancePeriods)
+		r.Get("/reimbursements/{reimbursementID}/receipt", receiptHandler.Download)
+		r.Get("/leave-types", leaveHandler.ListTypes)
+		r.With(authMiddleware.RequirePermission(models.PermAttendancePeriodsRead)).
+			Get("/attendance-periods", commonHandler.GetAttendancePeriods)
 	})
 
 	return r
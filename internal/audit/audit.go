@@ -0,0 +1,38 @@
+// Package audit carries who is making a request, and from where, through the
+// request context to the database, whose triggers record it in the audit log
+// alongside each change.
+package audit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Source identifies where a change comes from. ActorID is uuid.Nil when the
+// request is not authenticated.
+type Source struct {
+	ActorID   uuid.UUID
+	RequestID string
+	IPAddress string
+}
+
+type contextKey struct{}
+
+// WithSource returns a copy of ctx carrying source.
+func WithSource(ctx context.Context, source Source) context.Context {
+	return context.WithValue(ctx, contextKey{}, source)
+}
+
+// WithActor returns a copy of ctx whose source has actorID as its actor.
+func WithActor(ctx context.Context, actorID uuid.UUID) context.Context {
+	source := SourceFromContext(ctx)
+	source.ActorID = actorID
+	return WithSource(ctx, source)
+}
+
+// SourceFromContext returns the source stored in ctx, or the zero Source.
+func SourceFromContext(ctx context.Context) Source {
+	source, _ := ctx.Value(contextKey{}).(Source)
+	return source
+}
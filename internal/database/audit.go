@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jordanhimawan/payroll-mgmt/internal/audit"
+)
+
+// auditSessions passes the audit source of each request to the audit
+// triggers as session settings on the connections it checks out. Each
+// connection remembers the source it was last given, so checkouts that would
+// not change it skip the round trip.
+type auditSessions struct {
+	applied sync.Map // *pgx.Conn -> audit.Source
+}
+
+func (s *auditSessions) beforeAcquire(ctx context.Context, conn *pgx.Conn) bool {
+	source := audit.SourceFromContext(ctx)
+	last, _ := s.applied.Load(conn)
+	if current, _ := last.(audit.Source); current == source {
+		return true
+	}
+
+	actorID := ""
+	if source.ActorID != uuid.Nil {
+		actorID = source.ActorID.String()
+	}
+
+	_, err := conn.Exec(ctx, `
+		SELECT set_config('audit.actor_id', $1, false),
+			set_config('audit.request_id', $2, false),
+			set_config('audit.ip_address', $3, false)
+	`, actorID, source.RequestID, source.IPAddress)
+	if err != nil {
+		// Settings left from another request must not be used, so the
+		// connection is discarded.
+		s.applied.Delete(conn)
+		return false
+	}
+
+	s.applied.Store(conn, source)
+	return true
+}
+
+func (s *auditSessions) beforeClose(conn *pgx.Conn) {
+	s.applied.Delete(conn)
+}
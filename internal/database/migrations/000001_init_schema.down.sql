@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS reimbursements;
+DROP TABLE IF EXISTS overtimes;
+DROP TABLE IF EXISTS attendances;
+DROP TABLE IF EXISTS attendance_periods;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,83 @@
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    username VARCHAR(100) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    role VARCHAR(50) NOT NULL DEFAULT 'employee',
+    salary NUMERIC(15, 2),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id),
+    updated_by UUID REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS attendance_periods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    payroll_processed BOOLEAN NOT NULL DEFAULT false,
+    payroll_processed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID NOT NULL REFERENCES users(id),
+    updated_by UUID REFERENCES users(id),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attendance_periods_dates ON attendance_periods(start_date, end_date);
+
+CREATE TABLE IF NOT EXISTS attendances (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    attendance_period_id UUID NOT NULL REFERENCES attendance_periods(id),
+    attendance_date DATE NOT NULL,
+    check_in_time TIMESTAMPTZ,
+    check_out_time TIMESTAMPTZ,
+    is_present BOOLEAN NOT NULL DEFAULT true,
+    ip_address VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID NOT NULL REFERENCES users(id),
+    updated_by UUID REFERENCES users(id),
+    UNIQUE (user_id, attendance_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attendances_period_user ON attendances(attendance_period_id, user_id);
+
+CREATE TABLE IF NOT EXISTS overtimes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    attendance_period_id UUID NOT NULL REFERENCES attendance_periods(id),
+    overtime_date DATE NOT NULL,
+    hours_worked NUMERIC(4, 2) NOT NULL CHECK (hours_worked > 0 AND hours_worked <= 3),
+    description TEXT NOT NULL DEFAULT '',
+    ip_address VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID NOT NULL REFERENCES users(id),
+    updated_by UUID REFERENCES users(id),
+    UNIQUE (user_id, overtime_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_overtimes_period_user ON overtimes(attendance_period_id, user_id);
+
+CREATE TABLE IF NOT EXISTS reimbursements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    attendance_period_id UUID NOT NULL REFERENCES attendance_periods(id),
+    amount NUMERIC(15, 2) NOT NULL CHECK (amount > 0),
+    description TEXT NOT NULL,
+    receipt_url TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    ip_address VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID NOT NULL REFERENCES users(id),
+    updated_by UUID REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reimbursements_period_user ON reimbursements(attendance_period_id, user_id);
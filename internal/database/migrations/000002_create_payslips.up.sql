@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS payslips (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    attendance_period_id UUID NOT NULL REFERENCES attendance_periods(id),
+    base_salary NUMERIC(15, 2) NOT NULL,
+    working_days INTEGER NOT NULL,
+    attendance_days INTEGER NOT NULL,
+    prorated_salary NUMERIC(15, 2) NOT NULL,
+    overtime_hours NUMERIC(7, 2) NOT NULL DEFAULT 0,
+    overtime_pay NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    reimbursement_total NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    take_home_pay NUMERIC(15, 2) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID NOT NULL REFERENCES users(id),
+    UNIQUE (user_id, attendance_period_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payslips_attendance_period_id ON payslips(attendance_period_id);
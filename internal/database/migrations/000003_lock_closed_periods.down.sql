@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS attendance_periods_processed_guard ON attendance_periods;
+DROP TRIGGER IF EXISTS reimbursements_period_guard ON reimbursements;
+DROP TRIGGER IF EXISTS overtimes_period_guard ON overtimes;
+DROP TRIGGER IF EXISTS attendances_period_guard ON attendances;
+
+DROP FUNCTION IF EXISTS guard_processed_period();
+DROP FUNCTION IF EXISTS guard_attendance_period_writes();
+DROP FUNCTION IF EXISTS assert_attendance_period_open(UUID);
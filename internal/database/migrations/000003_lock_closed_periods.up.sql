@@ -0,0 +1,68 @@
+-- Reject writes to attendance, overtime and reimbursement rows whose period
+-- has been processed by payroll or deactivated, regardless of which code path
+-- issues them.
+CREATE OR REPLACE FUNCTION assert_attendance_period_open(p_period_id UUID) RETURNS void AS $$
+DECLARE
+    v_is_active BOOLEAN;
+    v_payroll_processed BOOLEAN;
+BEGIN
+    -- FOR SHARE serialises against the payroll run, which updates the period row.
+    SELECT is_active, payroll_processed
+    INTO v_is_active, v_payroll_processed
+    FROM attendance_periods
+    WHERE id = p_period_id
+    FOR SHARE;
+
+    IF NOT FOUND THEN
+        -- Let the foreign key report missing periods.
+        RETURN;
+    END IF;
+
+    IF v_payroll_processed OR NOT v_is_active THEN
+        RAISE EXCEPTION 'attendance period % is closed for changes', p_period_id
+            USING ERRCODE = 'PL001';
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION guard_attendance_period_writes() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM assert_attendance_period_open(OLD.attendance_period_id);
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM assert_attendance_period_open(NEW.attendance_period_id);
+        RETURN NEW;
+    END IF;
+
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attendances_period_guard
+    BEFORE INSERT OR UPDATE OR DELETE ON attendances
+    FOR EACH ROW EXECUTE FUNCTION guard_attendance_period_writes();
+
+CREATE TRIGGER overtimes_period_guard
+    BEFORE INSERT OR UPDATE OR DELETE ON overtimes
+    FOR EACH ROW EXECUTE FUNCTION guard_attendance_period_writes();
+
+CREATE TRIGGER reimbursements_period_guard
+    BEFORE INSERT OR UPDATE OR DELETE ON reimbursements
+    FOR EACH ROW EXECUTE FUNCTION guard_attendance_period_writes();
+
+-- A processed period cannot be reopened.
+CREATE OR REPLACE FUNCTION guard_processed_period() RETURNS trigger AS $$
+BEGIN
+    IF OLD.payroll_processed AND NOT NEW.payroll_processed THEN
+        RAISE EXCEPTION 'attendance period % has already been processed', OLD.id
+            USING ERRCODE = 'PL001';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attendance_periods_processed_guard
+    BEFORE UPDATE ON attendance_periods
+    FOR EACH ROW EXECUTE FUNCTION guard_processed_period();
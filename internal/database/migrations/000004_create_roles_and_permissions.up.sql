@@ -0,0 +1,74 @@
+CREATE TABLE IF NOT EXISTS roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS permissions (
+    name VARCHAR(100) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role VARCHAR(50) NOT NULL REFERENCES roles(name) ON DELETE CASCADE,
+    permission VARCHAR(100) NOT NULL REFERENCES permissions(name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access to every feature'),
+    ('hr', 'Manages people and attendance periods'),
+    ('finance', 'Runs and reviews payroll'),
+    ('manager', 'Reviews submissions from direct reports'),
+    ('auditor', 'Read-only access to payroll data'),
+    ('employee', 'Self-service submissions and payslips')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+    ('attendance_periods:read', 'List attendance periods'),
+    ('attendance_periods:manage', 'Create and edit attendance periods'),
+    ('payroll:run', 'Process payroll for an attendance period'),
+    ('payroll:read', 'View payroll summaries across employees'),
+    ('users:read', 'View user accounts'),
+    ('users:manage', 'Create, edit and deactivate user accounts'),
+    ('attendance:submit', 'Submit own attendance'),
+    ('overtime:submit', 'Submit own overtime'),
+    ('reimbursements:submit', 'Submit own reimbursements'),
+    ('payslips:read_own', 'View own payslips')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission)
+SELECT 'admin', name FROM permissions
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('hr', 'attendance_periods:read'),
+    ('hr', 'attendance_periods:manage'),
+    ('hr', 'payroll:read'),
+    ('hr', 'users:read'),
+    ('hr', 'users:manage'),
+    ('finance', 'attendance_periods:read'),
+    ('finance', 'payroll:run'),
+    ('finance', 'payroll:read'),
+    ('finance', 'users:read'),
+    ('manager', 'attendance_periods:read'),
+    ('manager', 'users:read'),
+    ('auditor', 'attendance_periods:read'),
+    ('auditor', 'payroll:read'),
+    ('auditor', 'users:read')
+ON CONFLICT DO NOTHING;
+
+-- Everyone except auditors and admins works through the employee self-service flows.
+INSERT INTO role_permissions (role, permission)
+SELECT r.role, p.permission
+FROM (VALUES ('employee'), ('hr'), ('finance'), ('manager')) AS r(role)
+CROSS JOIN (VALUES
+    ('attendance_periods:read'),
+    ('attendance:submit'),
+    ('overtime:submit'),
+    ('reimbursements:submit'),
+    ('payslips:read_own')
+) AS p(permission)
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE users
+    ADD CONSTRAINT fk_users_role FOREIGN KEY (role) REFERENCES roles(name);
@@ -0,0 +1,13 @@
+DELETE FROM permissions WHERE name = 'overtime:approve';
+
+DROP INDEX IF EXISTS idx_overtimes_status;
+
+ALTER TABLE overtimes
+    DROP COLUMN IF EXISTS review_reason,
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS reviewed_by,
+    DROP COLUMN IF EXISTS status;
+
+DROP INDEX IF EXISTS idx_users_manager_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS manager_id;
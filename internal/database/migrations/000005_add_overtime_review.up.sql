@@ -0,0 +1,25 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS manager_id UUID REFERENCES users(id);
+
+CREATE INDEX IF NOT EXISTS idx_users_manager_id ON users(manager_id);
+
+-- Overtime recorded before the review workflow existed was final, so it is
+-- backfilled as approved; new rows start out pending.
+ALTER TABLE overtimes
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'approved'
+        CHECK (status IN ('pending', 'approved', 'rejected')),
+    ADD COLUMN IF NOT EXISTS reviewed_by UUID REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS review_reason TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE overtimes ALTER COLUMN status SET DEFAULT 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_overtimes_status ON overtimes(status);
+
+INSERT INTO permissions (name, description) VALUES
+    ('overtime:approve', 'Approve or reject overtime of direct reports')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('manager', 'overtime:approve')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,23 @@
+DROP TRIGGER IF EXISTS reimbursements_period_guard ON reimbursements;
+CREATE TRIGGER reimbursements_period_guard
+    BEFORE INSERT OR UPDATE OR DELETE ON reimbursements
+    FOR EACH ROW EXECUTE FUNCTION guard_attendance_period_writes();
+DROP FUNCTION IF EXISTS guard_reimbursement_period_writes();
+
+DELETE FROM permissions WHERE name IN ('reimbursements:read', 'reimbursements:approve');
+
+DROP INDEX IF EXISTS idx_reimbursements_status;
+
+-- Paid rows mostly sit in processed periods, which the period guard protects.
+ALTER TABLE reimbursements DISABLE TRIGGER reimbursements_period_guard;
+UPDATE reimbursements SET status = 'approved' WHERE status = 'paid';
+ALTER TABLE reimbursements ENABLE TRIGGER reimbursements_period_guard;
+
+ALTER TABLE reimbursements
+    DROP COLUMN IF EXISTS paid_at,
+    DROP COLUMN IF EXISTS review_reason,
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS reviewed_by,
+    DROP CONSTRAINT IF EXISTS reimbursements_status_check,
+    ADD CONSTRAINT reimbursements_status_check
+        CHECK (status IN ('pending', 'approved', 'rejected'));
@@ -0,0 +1,63 @@
+ALTER TABLE reimbursements
+    DROP CONSTRAINT IF EXISTS reimbursements_status_check,
+    ADD CONSTRAINT reimbursements_status_check
+        CHECK (status IN ('pending', 'approved', 'rejected', 'paid')),
+    ADD COLUMN IF NOT EXISTS reviewed_by UUID REFERENCES users(id),
+    ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS review_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS paid_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_reimbursements_status ON reimbursements(status);
+
+INSERT INTO permissions (name, description) VALUES
+    ('reimbursements:read', 'List reimbursements across employees'),
+    ('reimbursements:approve', 'Approve or reject reimbursements')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'reimbursements:read'),
+    ('admin', 'reimbursements:approve'),
+    ('finance', 'reimbursements:read'),
+    ('finance', 'reimbursements:approve'),
+    ('hr', 'reimbursements:read'),
+    ('auditor', 'reimbursements:read')
+ON CONFLICT DO NOTHING;
+
+-- Payroll pays out the approved reimbursements of the period it processes,
+-- and periods are usually deactivated to close submissions before that run.
+-- Only a processed period refuses the approved to paid transition.
+CREATE OR REPLACE FUNCTION guard_reimbursement_period_writes() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND OLD.status = 'approved' AND NEW.status = 'paid'
+        AND OLD.attendance_period_id = NEW.attendance_period_id THEN
+        -- FOR SHARE serialises against the payroll run, as in
+        -- assert_attendance_period_open.
+        IF EXISTS (
+            SELECT 1 FROM attendance_periods
+            WHERE id = NEW.attendance_period_id AND payroll_processed
+            FOR SHARE
+        ) THEN
+            RAISE EXCEPTION 'attendance period % is closed for changes', NEW.attendance_period_id
+                USING ERRCODE = 'PL001';
+        END IF;
+        RETURN NEW;
+    END IF;
+
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM assert_attendance_period_open(OLD.attendance_period_id);
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM assert_attendance_period_open(NEW.attendance_period_id);
+        RETURN NEW;
+    END IF;
+
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS reimbursements_period_guard ON reimbursements;
+CREATE TRIGGER reimbursements_period_guard
+    BEFORE INSERT OR UPDATE OR DELETE ON reimbursements
+    FOR EACH ROW EXECUTE FUNCTION guard_reimbursement_period_writes();
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_reimbursements_receipt_sha256;
+
+ALTER TABLE reimbursements
+    DROP COLUMN IF EXISTS receipt_sha256,
+    DROP COLUMN IF EXISTS receipt_size,
+    DROP COLUMN IF EXISTS receipt_content_type,
+    DROP COLUMN IF EXISTS receipt_file_name,
+    DROP COLUMN IF EXISTS receipt_key;
@@ -0,0 +1,11 @@
+ALTER TABLE reimbursements
+    ADD COLUMN IF NOT EXISTS receipt_key TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS receipt_file_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS receipt_content_type VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS receipt_size BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS receipt_sha256 VARCHAR(64) NOT NULL DEFAULT '';
+
+-- The same receipt may not back two live claims; rejected claims free it up.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reimbursements_receipt_sha256
+    ON reimbursements(receipt_sha256)
+    WHERE receipt_sha256 <> '' AND status <> 'rejected';
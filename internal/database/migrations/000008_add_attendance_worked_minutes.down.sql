@@ -0,0 +1,3 @@
+ALTER TABLE attendances
+    DROP CONSTRAINT IF EXISTS attendances_check_out_after_check_in,
+    DROP COLUMN IF EXISTS worked_minutes;
@@ -0,0 +1,18 @@
+ALTER TABLE attendances
+    ADD COLUMN IF NOT EXISTS worked_minutes INTEGER CHECK (worked_minutes >= 0);
+
+-- Backfilling rows in processed periods would trip the period guard.
+ALTER TABLE attendances DISABLE TRIGGER attendances_period_guard;
+
+UPDATE attendances
+SET worked_minutes = FLOOR(EXTRACT(EPOCH FROM (check_out_time - check_in_time)) / 60)
+WHERE check_in_time IS NOT NULL
+  AND check_out_time IS NOT NULL
+  AND check_out_time >= check_in_time;
+
+ALTER TABLE attendances ENABLE TRIGGER attendances_period_guard;
+
+ALTER TABLE attendances
+    ADD CONSTRAINT attendances_check_out_after_check_in
+        CHECK (check_out_time IS NULL OR (check_in_time IS NOT NULL AND check_out_time >= check_in_time))
+        NOT VALID;
@@ -0,0 +1,11 @@
+ALTER TABLE overtimes
+    DROP CONSTRAINT IF EXISTS overtimes_hours_worked_check,
+    ADD CONSTRAINT overtimes_hours_worked_check CHECK (hours_worked > 0 AND hours_worked <= 3) NOT VALID;
+
+DELETE FROM permissions WHERE name = 'calendar:manage';
+
+DROP TABLE IF EXISTS holidays;
+
+ALTER TABLE users DROP COLUMN IF EXISTS location_id;
+
+DROP TABLE IF EXISTS locations;
@@ -0,0 +1,52 @@
+CREATE TABLE IF NOT EXISTS locations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    -- Days of the week nobody works, 0 = Sunday through 6 = Saturday.
+    weekend_days SMALLINT[] NOT NULL DEFAULT '{0,6}'
+        CHECK (weekend_days <@ ARRAY[0, 1, 2, 3, 4, 5, 6]::SMALLINT[]),
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id),
+    updated_by UUID REFERENCES users(id)
+);
+
+-- Users without a location follow the default one.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_locations_single_default ON locations(is_default) WHERE is_default;
+
+INSERT INTO locations (name, weekend_days, is_default)
+VALUES ('Head Office', '{0,6}', true)
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS location_id UUID REFERENCES locations(id);
+
+CREATE TABLE IF NOT EXISTS holidays (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    holiday_date DATE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('public_holiday', 'company_closure')),
+    -- NULL applies the holiday to every location.
+    location_id UUID REFERENCES locations(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID NOT NULL REFERENCES users(id),
+    updated_by UUID REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_holidays_date_location
+    ON holidays(holiday_date, COALESCE(location_id, '00000000-0000-0000-0000-000000000000'::UUID));
+
+INSERT INTO permissions (name, description) VALUES
+    ('calendar:manage', 'Manage locations, public holidays and company closures')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'calendar:manage'),
+    ('hr', 'calendar:manage')
+ON CONFLICT DO NOTHING;
+
+-- Overtime on weekends and holidays may cover a full working day. The
+-- three-hour cap on working days is enforced by the service.
+ALTER TABLE overtimes
+    DROP CONSTRAINT IF EXISTS overtimes_hours_worked_check,
+    ADD CONSTRAINT overtimes_hours_worked_check CHECK (hours_worked > 0 AND hours_worked <= 8);
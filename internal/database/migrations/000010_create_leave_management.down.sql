@@ -0,0 +1,10 @@
+DELETE FROM permissions WHERE name IN ('leave:submit', 'leave:approve');
+
+ALTER TABLE payslips DROP COLUMN IF EXISTS paid_leave_days;
+
+DROP TABLE IF EXISTS leave_requests;
+DROP TABLE IF EXISTS leave_entitlements;
+DROP TABLE IF EXISTS leave_types;
+
+DROP FUNCTION IF EXISTS guard_leave_request_writes();
+DROP FUNCTION IF EXISTS assert_leave_unpaid(UUID, DATE, DATE);
@@ -0,0 +1,108 @@
+CREATE TABLE IF NOT EXISTS leave_types (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    is_paid BOOLEAN NOT NULL,
+    -- Days granted per year. NULL means the type is not limited by a balance.
+    annual_days NUMERIC(5, 2) CHECK (annual_days >= 0),
+    -- 'upfront' grants the whole year on January 1st, 'monthly' a twelfth
+    -- at the start of every month.
+    accrual VARCHAR(20) NOT NULL DEFAULT 'upfront' CHECK (accrual IN ('upfront', 'monthly'))
+);
+
+INSERT INTO leave_types (code, name, is_paid, annual_days, accrual) VALUES
+    ('annual', 'Annual leave', true, 12, 'monthly'),
+    ('sick', 'Sick leave', true, 12, 'upfront'),
+    ('unpaid', 'Unpaid leave', false, NULL, 'upfront')
+ON CONFLICT (code) DO NOTHING;
+
+-- Per-user overrides of a leave type's annual days.
+CREATE TABLE IF NOT EXISTS leave_entitlements (
+    user_id UUID NOT NULL REFERENCES users(id),
+    leave_type VARCHAR(50) NOT NULL REFERENCES leave_types(code),
+    year INTEGER NOT NULL,
+    entitled_days NUMERIC(5, 2) NOT NULL CHECK (entitled_days >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID NOT NULL REFERENCES users(id),
+    updated_by UUID REFERENCES users(id),
+    PRIMARY KEY (user_id, leave_type, year)
+);
+
+CREATE TABLE IF NOT EXISTS leave_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    leave_type VARCHAR(50) NOT NULL REFERENCES leave_types(code),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    -- Working days covered, per the user's calendar when the request was made.
+    days INTEGER NOT NULL CHECK (days > 0),
+    reason TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'cancelled')),
+    reviewed_by UUID REFERENCES users(id),
+    reviewed_at TIMESTAMPTZ,
+    review_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID NOT NULL REFERENCES users(id),
+    updated_by UUID REFERENCES users(id),
+    CONSTRAINT leave_requests_date_range CHECK (end_date >= start_date),
+    CONSTRAINT leave_requests_single_year CHECK (EXTRACT(YEAR FROM start_date) = EXTRACT(YEAR FROM end_date))
+);
+
+CREATE INDEX IF NOT EXISTS idx_leave_requests_user_dates ON leave_requests(user_id, start_date, end_date);
+CREATE INDEX IF NOT EXISTS idx_leave_requests_status ON leave_requests(status);
+
+-- Paid leave counts towards attendance_days; this keeps the breakdown.
+ALTER TABLE payslips ADD COLUMN IF NOT EXISTS paid_leave_days INTEGER NOT NULL DEFAULT 0;
+
+INSERT INTO permissions (name, description) VALUES
+    ('leave:submit', 'Request own leave'),
+    ('leave:approve', 'Approve or reject leave of direct reports')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'leave:submit'),
+    ('manager', 'leave:approve'),
+    ('employee', 'leave:submit'),
+    ('hr', 'leave:submit'),
+    ('finance', 'leave:submit'),
+    ('manager', 'leave:submit')
+ON CONFLICT DO NOTHING;
+
+-- Paid leave counts towards the attendance on a payslip, so a request that
+-- overlaps a period the user has been paid for cannot be added, reviewed or
+-- cancelled. The payroll run holds a SHARE lock on this table until its
+-- payslips are stored, so a write that had to wait for it sees them here.
+CREATE OR REPLACE FUNCTION assert_leave_unpaid(p_user_id UUID, p_start DATE, p_end DATE) RETURNS void AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM payslips p
+        JOIN attendance_periods ap ON ap.id = p.attendance_period_id
+        WHERE p.user_id = p_user_id AND ap.start_date <= p_end AND ap.end_date >= p_start
+    ) THEN
+        RAISE EXCEPTION 'leave of user % from % to % overlaps a processed payroll', p_user_id, p_start, p_end
+            USING ERRCODE = 'PL001';
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION guard_leave_request_writes() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM assert_leave_unpaid(OLD.user_id, OLD.start_date, OLD.end_date);
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM assert_leave_unpaid(NEW.user_id, NEW.start_date, NEW.end_date);
+        RETURN NEW;
+    END IF;
+
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER leave_requests_paid_guard
+    BEFORE INSERT OR UPDATE OR DELETE ON leave_requests
+    FOR EACH ROW EXECUTE FUNCTION guard_leave_request_writes();
@@ -0,0 +1,15 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS salary NUMERIC(15, 2);
+
+UPDATE users u
+SET salary = (
+    SELECT s.amount
+    FROM salary_history s
+    WHERE s.user_id = u.id AND s.effective_from <= CURRENT_DATE
+    ORDER BY s.effective_from DESC
+    LIMIT 1
+);
+
+DROP TABLE IF EXISTS salary_history;
+
+DROP FUNCTION IF EXISTS guard_salary_history_writes();
+DROP FUNCTION IF EXISTS assert_salary_unpaid(UUID, DATE);
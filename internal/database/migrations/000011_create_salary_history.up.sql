@@ -0,0 +1,62 @@
+CREATE TABLE IF NOT EXISTS salary_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id),
+    amount NUMERIC(15, 2) NOT NULL CHECK (amount > 0),
+    effective_from DATE NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id),
+    UNIQUE (user_id, effective_from)
+);
+
+-- Existing salaries were used for every payroll run so far, so they take
+-- effect no later than the first attendance period.
+INSERT INTO salary_history (user_id, amount, effective_from, reason, created_by)
+SELECT u.id,
+       u.salary,
+       LEAST(u.created_at::DATE, COALESCE((SELECT MIN(start_date) FROM attendance_periods), u.created_at::DATE)),
+       'Initial salary',
+       u.created_by
+FROM users u
+WHERE u.salary IS NOT NULL
+ON CONFLICT (user_id, effective_from) DO NOTHING;
+
+ALTER TABLE users DROP COLUMN IF EXISTS salary;
+
+-- Payslips are computed from the salaries in force during their period, so a
+-- salary cannot be added, changed or removed from a date on or before the
+-- end of a period the user has been paid for. The payroll run holds a SHARE
+-- lock on this table until its payslips are stored, so a change that had to
+-- wait for it sees them here.
+CREATE OR REPLACE FUNCTION assert_salary_unpaid(p_user_id UUID, p_effective_from DATE) RETURNS void AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM payslips p
+        JOIN attendance_periods ap ON ap.id = p.attendance_period_id
+        WHERE p.user_id = p_user_id AND ap.end_date >= p_effective_from
+    ) THEN
+        RAISE EXCEPTION 'salary of user % from % has already been paid', p_user_id, p_effective_from
+            USING ERRCODE = 'PL001';
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION guard_salary_history_writes() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM assert_salary_unpaid(OLD.user_id, OLD.effective_from);
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM assert_salary_unpaid(NEW.user_id, NEW.effective_from);
+        RETURN NEW;
+    END IF;
+
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER salary_history_paid_guard
+    BEFORE INSERT OR UPDATE OR DELETE ON salary_history
+    FOR EACH ROW EXECUTE FUNCTION guard_salary_history_writes();
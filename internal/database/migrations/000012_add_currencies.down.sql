@@ -0,0 +1,8 @@
+DELETE FROM permissions WHERE name = 'exchange_rates:manage';
+
+DROP TABLE IF EXISTS payslip_exchange_rates;
+DROP TABLE IF EXISTS exchange_rates;
+
+ALTER TABLE payslips DROP COLUMN IF EXISTS currency;
+ALTER TABLE salary_history DROP COLUMN IF EXISTS currency;
+ALTER TABLE reimbursements DROP COLUMN IF EXISTS currency;
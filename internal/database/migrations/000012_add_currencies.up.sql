@@ -0,0 +1,46 @@
+-- Amounts so far were all in the company's home currency.
+ALTER TABLE reimbursements
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR' CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE salary_history
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR' CHECK (currency ~ '^[A-Z]{3}$');
+
+-- A payslip is in the currency of the salary in force at the end of its period.
+ALTER TABLE payslips
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+
+-- One unit of from_currency is worth rate units of to_currency from rate_date
+-- until the next rate for the pair.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    from_currency CHAR(3) NOT NULL CHECK (from_currency ~ '^[A-Z]{3}$'),
+    to_currency CHAR(3) NOT NULL CHECK (to_currency ~ '^[A-Z]{3}$'),
+    rate NUMERIC(18, 8) NOT NULL CHECK (rate > 0),
+    rate_date DATE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id),
+    updated_by UUID REFERENCES users(id),
+    CHECK (from_currency <> to_currency),
+    UNIQUE (from_currency, to_currency, rate_date)
+);
+
+-- The rates a payslip was converted with, copied so later rate edits do not
+-- change how a processed payslip reads.
+CREATE TABLE IF NOT EXISTS payslip_exchange_rates (
+    payslip_id UUID NOT NULL REFERENCES payslips(id) ON DELETE CASCADE,
+    from_currency CHAR(3) NOT NULL,
+    to_currency CHAR(3) NOT NULL,
+    rate NUMERIC(18, 8) NOT NULL,
+    rate_date DATE NOT NULL,
+    PRIMARY KEY (payslip_id, from_currency)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('exchange_rates:manage', 'Maintain the exchange rates used by payroll')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'exchange_rates:manage'),
+    ('finance', 'exchange_rates:manage')
+ON CONFLICT DO NOTHING;
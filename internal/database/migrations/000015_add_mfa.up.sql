@@ -0,0 +1,31 @@
+-- TOTP two-factor authentication. mfa_secret is set when enrolment starts and
+-- only counts once mfa_enabled is true. mfa_last_step is the time step of the
+-- last accepted code, so a code cannot be used twice.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS mfa_enabled BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS mfa_secret VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS mfa_last_step BIGINT,
+    ADD COLUMN IF NOT EXISTS mfa_enabled_at TIMESTAMPTZ;
+
+-- Single-use recovery codes, stored as SHA-256 hashes.
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, code_hash)
+);
+
+-- The second step of a login. Login hands out the token, stored here as a
+-- SHA-256 hash, once the password checks out; purpose says whether it is
+-- redeemed with a code or used to enrol first.
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    purpose VARCHAR(10) NOT NULL CHECK (purpose IN ('verify', 'enroll')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,35 @@
+-- must_change_password is set on passwords an admin chose, so the user
+-- replaces them at their next login.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS must_change_password BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMPTZ;
+
+-- Every password hash a user has had, including the current one, so recent
+-- passwords cannot be reused.
+CREATE TABLE IF NOT EXISTS password_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_history_user ON password_history(user_id, created_at DESC);
+
+INSERT INTO password_history (user_id, password_hash)
+SELECT id, password_hash FROM users u
+WHERE NOT EXISTS (SELECT 1 FROM password_history h WHERE h.user_id = u.id);
+
+-- One-time tokens for setting a new password without the old one: issued by
+-- an admin, or by login when the password must be changed. Only the SHA-256
+-- hash is stored.
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user ON password_reset_tokens(user_id);
@@ -0,0 +1,23 @@
+DROP TRIGGER IF EXISTS exchange_rates_audit ON exchange_rates;
+DROP TRIGGER IF EXISTS salary_history_audit ON salary_history;
+DROP TRIGGER IF EXISTS leave_requests_audit ON leave_requests;
+DROP TRIGGER IF EXISTS leave_entitlements_audit ON leave_entitlements;
+DROP TRIGGER IF EXISTS leave_types_audit ON leave_types;
+DROP TRIGGER IF EXISTS holidays_audit ON holidays;
+DROP TRIGGER IF EXISTS locations_audit ON locations;
+DROP TRIGGER IF EXISTS payslip_exchange_rates_audit ON payslip_exchange_rates;
+DROP TRIGGER IF EXISTS payslips_audit ON payslips;
+DROP TRIGGER IF EXISTS reimbursements_audit ON reimbursements;
+DROP TRIGGER IF EXISTS overtimes_audit ON overtimes;
+DROP TRIGGER IF EXISTS attendances_audit ON attendances;
+DROP TRIGGER IF EXISTS attendance_periods_audit ON attendance_periods;
+DROP TRIGGER IF EXISTS role_permissions_audit ON role_permissions;
+DROP TRIGGER IF EXISTS roles_audit ON roles;
+DROP TRIGGER IF EXISTS users_audit ON users;
+
+DROP FUNCTION IF EXISTS record_audit_log();
+
+DELETE FROM permissions WHERE name = 'audit_logs:read';
+
+DROP TABLE IF EXISTS audit_logs;
+DROP FUNCTION IF EXISTS guard_audit_logs();
@@ -0,0 +1,180 @@
+-- Append-only history of changes to business data. Rows are written by the
+-- record_audit_log trigger in the same transaction as the change, so every
+-- code path is covered. Who made the change, and from where, is read from
+-- the audit.* session settings the application sets on each connection it
+-- checks out for a request.
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor_id UUID,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('insert', 'update', 'delete')),
+    entity_type VARCHAR(100) NOT NULL,
+    entity_id TEXT NOT NULL,
+    before JSONB,
+    after JSONB,
+    request_id TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs(entity_type, entity_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs(created_at DESC);
+
+CREATE OR REPLACE FUNCTION guard_audit_logs() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_logs_append_only
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION guard_audit_logs();
+
+CREATE TRIGGER audit_logs_no_truncate
+    BEFORE TRUNCATE ON audit_logs
+    FOR EACH STATEMENT EXECUTE FUNCTION guard_audit_logs();
+
+-- record_audit_log takes up to three comma-separated column lists: the key
+-- columns that identify the row, columns whose values are secret (changes to
+-- them are logged as "[redacted]"), and columns whose changes are not worth
+-- logging at all. Updates record only the columns that changed, and updates
+-- that change nothing else are skipped.
+CREATE OR REPLACE FUNCTION record_audit_log() RETURNS trigger AS $$
+DECLARE
+    v_row JSONB;
+    v_before JSONB;
+    v_after JSONB;
+    v_entity_id TEXT;
+    v_column TEXT;
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        v_before := to_jsonb(OLD);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        v_after := to_jsonb(NEW);
+    END IF;
+    v_row := COALESCE(v_after, v_before);
+
+    SELECT string_agg(v_row ->> k.key_column, '/' ORDER BY k.n)
+    INTO v_entity_id
+    FROM unnest(string_to_array(TG_ARGV[0], ',')) WITH ORDINALITY AS k(key_column, n);
+
+    IF TG_NARGS > 2 THEN
+        FOREACH v_column IN ARRAY string_to_array(TG_ARGV[2], ',') LOOP
+            v_before := v_before - v_column;
+            v_after := v_after - v_column;
+        END LOOP;
+    END IF;
+
+    IF TG_OP = 'UPDATE' THEN
+        FOR v_column IN SELECT jsonb_object_keys(v_after) LOOP
+            IF v_before -> v_column = v_after -> v_column THEN
+                v_before := v_before - v_column;
+                v_after := v_after - v_column;
+            END IF;
+        END LOOP;
+
+        IF v_after = '{}'::jsonb THEN
+            RETURN NULL;
+        END IF;
+    END IF;
+
+    IF TG_NARGS > 1 THEN
+        FOREACH v_column IN ARRAY string_to_array(TG_ARGV[1], ',') LOOP
+            IF v_before ? v_column THEN
+                v_before := jsonb_set(v_before, ARRAY[v_column], '"[redacted]"');
+            END IF;
+            IF v_after ? v_column THEN
+                v_after := jsonb_set(v_after, ARRAY[v_column], '"[redacted]"');
+            END IF;
+        END LOOP;
+    END IF;
+
+    INSERT INTO audit_logs (actor_id, action, entity_type, entity_id, before, after, request_id, ip_address)
+    VALUES (
+        NULLIF(current_setting('audit.actor_id', true), '')::UUID,
+        lower(TG_OP),
+        TG_TABLE_NAME,
+        v_entity_id,
+        v_before,
+        v_after,
+        COALESCE(current_setting('audit.request_id', true), ''),
+        COALESCE(current_setting('audit.ip_address', true), '')
+    );
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_audit
+    AFTER INSERT OR UPDATE OR DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('id', 'password_hash,mfa_secret', 'mfa_last_step');
+
+CREATE TRIGGER roles_audit
+    AFTER INSERT OR UPDATE OR DELETE ON roles
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('name');
+
+CREATE TRIGGER role_permissions_audit
+    AFTER INSERT OR UPDATE OR DELETE ON role_permissions
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('role,permission');
+
+CREATE TRIGGER attendance_periods_audit
+    AFTER INSERT OR UPDATE OR DELETE ON attendance_periods
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('id');
+
+CREATE TRIGGER attendances_audit
+    AFTER INSERT OR UPDATE OR DELETE ON attendances
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('id');
+
+CREATE TRIGGER overtimes_audit
+    AFTER INSERT OR UPDATE OR DELETE ON overtimes
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('id');
+
+CREATE TRIGGER reimbursements_audit
+    AFTER INSERT OR UPDATE OR DELETE ON reimbursements
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('id');
+
+CREATE TRIGGER payslips_audit
+    AFTER INSERT OR UPDATE OR DELETE ON payslips
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('id');
+
+CREATE TRIGGER payslip_exchange_rates_audit
+    AFTER INSERT OR UPDATE OR DELETE ON payslip_exchange_rates
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('payslip_id,from_currency');
+
+CREATE TRIGGER locations_audit
+    AFTER INSERT OR UPDATE OR DELETE ON locations
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('id');
+
+CREATE TRIGGER holidays_audit
+    AFTER INSERT OR UPDATE OR DELETE ON holidays
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('id');
+
+CREATE TRIGGER leave_types_audit
+    AFTER INSERT OR UPDATE OR DELETE ON leave_types
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('code');
+
+CREATE TRIGGER leave_entitlements_audit
+    AFTER INSERT OR UPDATE OR DELETE ON leave_entitlements
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('user_id,leave_type,year');
+
+CREATE TRIGGER leave_requests_audit
+    AFTER INSERT OR UPDATE OR DELETE ON leave_requests
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('id');
+
+CREATE TRIGGER salary_history_audit
+    AFTER INSERT OR UPDATE OR DELETE ON salary_history
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('id');
+
+CREATE TRIGGER exchange_rates_audit
+    AFTER INSERT OR UPDATE OR DELETE ON exchange_rates
+    FOR EACH ROW EXECUTE FUNCTION record_audit_log('id');
+
+INSERT INTO permissions (name, description) VALUES
+    ('audit_logs:read', 'View the audit log of changes')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'audit_logs:read'),
+    ('auditor', 'audit_logs:read')
+ON CONFLICT DO NOTHING;
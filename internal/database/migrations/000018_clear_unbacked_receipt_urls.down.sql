@@ -0,0 +1 @@
+-- The cleared links are not kept, so there is nothing to restore.
@@ -0,0 +1,10 @@
+-- Receipt URLs used to be taken from the client as free text. They now only
+-- point at a receipt that was uploaded, so links without one are dropped.
+-- Clearing rows in processed periods would trip the period guard.
+ALTER TABLE reimbursements DISABLE TRIGGER reimbursements_period_guard;
+
+UPDATE reimbursements
+SET receipt_url = ''
+WHERE receipt_key = '' AND receipt_url <> '';
+
+ALTER TABLE reimbursements ENABLE TRIGGER reimbursements_period_guard;
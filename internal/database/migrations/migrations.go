@@ -0,0 +1,8 @@
+// Package migrations embeds the versioned SQL schema migrations. Files are
+// named NNNNNN_description.up.sql / NNNNNN_description.down.sql.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
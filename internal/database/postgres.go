@@ -17,6 +17,10 @@ func NewPostgresConnection(databaseURL string) (*pgxpool.Pool, error) {
 	config.MaxConns = 30
 	config.MinConns = 5
 
+	sessions := &auditSessions{}
+	config.BeforeAcquire = sessions.beforeAcquire
+	config.BeforeClose = sessions.beforeClose
+
 	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+	"github.com/jordanhimawan/payroll-mgmt/internal/services"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/response"
+)
+
+type AdminHandler struct {
+	periodRepo           repository.AttendancePeriodRepository
+	payrollService       *services.PayrollService
+	reimbursementService *services.ReimbursementService
+}
+
+func NewAdminHandler(
+	periodRepo repository.AttendancePeriodRepository,
+	payrollService *services.PayrollService,
+	reimbursementService *services.ReimbursementService,
+) *AdminHandler {
+	return &AdminHandler{
+		periodRepo:           periodRepo,
+		payrollService:       payrollService,
+		reimbursementService: reimbursementService,
+	}
+}
+
+func (h *AdminHandler) CreateAttendancePeriod(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAttendancePeriodRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Name == "" {
+		response.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		response.Error(w, "Invalid start date format", http.StatusBadRequest)
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		response.Error(w, "Invalid end date format", http.StatusBadRequest)
+		return
+	}
+
+	if endDate.Before(startDate) {
+		response.Error(w, "End date must be after start date", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	period := &models.AttendancePeriod{
+		Name:      req.Name,
+		StartDate: startDate,
+		EndDate:   endDate,
+		CreatedBy: userID,
+	}
+
+	if err := h.periodRepo.Create(r.Context(), period); err != nil {
+		response.Error(w, "Failed to create attendance period", http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, period, http.StatusCreated)
+}
+
+func (h *AdminHandler) RunPayroll(w http.ResponseWriter, r *http.Request) {
+	periodID, err := uuid.Parse(chi.URLParam(r, "periodID"))
+	if err != nil {
+		response.Error(w, "Invalid attendance period ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.payrollService.RunPayroll(r.Context(), periodID, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, result, http.StatusOK)
+}
+
+func (h *AdminHandler) GetPayrollSummary(w http.ResponseWriter, r *http.Request) {
+	periodID, err := uuid.Parse(chi.URLParam(r, "periodID"))
+	if err != nil {
+		response.Error(w, "Invalid attendance period ID", http.StatusBadRequest)
+		return
+	}
+
+	params, err := parsePageParams(r, "username")
+	if err != nil {
+		response.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.payrollService.GetSummary(r.Context(), periodID, params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, summary, http.StatusOK)
+}
+
+func (h *AdminHandler) ListReimbursements(w http.ResponseWriter, r *http.Request) {
+	params, err := parsePageParams(r, "created_at")
+	if err != nil {
+		response.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.ReimbursementFilter{Status: query.Get("status")}
+
+	if v := query.Get("user_id"); v != "" {
+		userID, err := uuid.Parse(v)
+		if err != nil {
+			response.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	if v := query.Get("period_id"); v != "" {
+		periodID, err := uuid.Parse(v)
+		if err != nil {
+			response.Error(w, "Invalid attendance period ID", http.StatusBadRequest)
+			return
+		}
+		filter.PeriodID = &periodID
+	}
+
+	result, err := h.reimbursementService.ListReimbursements(r.Context(), filter, params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, result, http.StatusOK)
+}
+
+func (h *AdminHandler) ApproveReimbursement(w http.ResponseWriter, r *http.Request) {
+	h.reviewReimbursement(w, r, true)
+}
+
+func (h *AdminHandler) RejectReimbursement(w http.ResponseWriter, r *http.Request) {
+	h.reviewReimbursement(w, r, false)
+}
+
+func (h *AdminHandler) reviewReimbursement(w http.ResponseWriter, r *http.Request, approve bool) {
+	reimbursementID, err := uuid.Parse(chi.URLParam(r, "reimbursementID"))
+	if err != nil {
+		response.Error(w, "Invalid reimbursement ID", http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional when approving.
+	var req models.ReviewRequest
+	if err := decodeOptionalBody(r, &req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	reviewerID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	reimbursement, err := h.reimbursementService.Review(r.Context(), reviewerID, reimbursementID, approve, req.Reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, reimbursement, http.StatusOK)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/services"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/response"
+)
+
+type AuditHandler struct {
+	auditService *services.AuditService
+}
+
+func NewAuditHandler(auditService *services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListAuditLogs lists audit log entries, newest first, optionally filtered by
+// entity_type (a table name such as overtimes), entity_id, actor_id and an
+// RFC 3339 time range from (inclusive) to (exclusive). It takes page and
+// page_size.
+func (h *AuditHandler) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	params, err := parsePageParams(r, "created_at")
+	if err != nil {
+		response.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.AuditLogFilter{
+		EntityType: query.Get("entity_type"),
+		EntityID:   query.Get("entity_id"),
+	}
+
+	if v := query.Get("actor_id"); v != "" {
+		actorID, err := uuid.Parse(v)
+		if err != nil {
+			response.Error(w, "Invalid actor ID", http.StatusBadRequest)
+			return
+		}
+		filter.ActorID = &actorID
+	}
+
+	if v := query.Get("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			response.Error(w, "Invalid from time format", http.StatusBadRequest)
+			return
+		}
+		filter.From = from
+	}
+
+	if v := query.Get("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			response.Error(w, "Invalid to time format", http.StatusBadRequest)
+			return
+		}
+		filter.To = to
+	}
+
+	result, err := h.auditService.ListAuditLogs(r.Context(), filter, params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, result, http.StatusOK)
+}
@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/middleware"
 	"github.com/jordanhimawan/payroll-mgmt/internal/models"
 	"github.com/jordanhimawan/payroll-mgmt/internal/services"
 	"github.com/jordanhimawan/payroll-mgmt/pkg/response"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/utils"
 )
 
 type AuthHandler struct {
@@ -32,7 +35,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	loginResp, err := h.authService.Login(r.Context(), req.Username, req.Password)
+	loginResp, err := h.authService.Login(r.Context(), req.Username, req.Password, utils.GetClientIP(r), r.UserAgent())
 	if err != nil {
 		if appErr, ok := err.(*services.AppError); ok {
 			response.Error(w, appErr.Message, appErr.Code)
@@ -44,3 +47,241 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	response.JSON(w, loginResp, http.StatusOK)
 }
+
+// Refresh exchanges a refresh token for a new access and refresh token. The
+// presented refresh token cannot be used again.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.RefreshToken == "" {
+		response.Error(w, "Refresh token is required", http.StatusBadRequest)
+		return
+	}
+
+	loginResp, err := h.authService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, loginResp, http.StatusOK)
+}
+
+// Logout revokes the caller's session, or all of their sessions when
+// all_sessions is set. The body is optional.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req models.LogoutRequest
+	if err := decodeOptionalBody(r, &req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.authService.Logout(r.Context(), claims, req.AllSessions); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// JWKS publishes the public keys access tokens are signed with, so other
+// services can verify them. Clients should refetch on an unknown kid.
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	response.JSON(w, h.authService.JWKS(), http.StatusOK)
+}
+
+// VerifyMFA finishes a login with a TOTP or recovery code and the MFA token
+// login returned.
+func (h *AuthHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var req models.MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.MFAToken == "" || req.Code == "" {
+		response.Error(w, "MFA token and code are required", http.StatusBadRequest)
+		return
+	}
+
+	loginResp, err := h.authService.VerifyMFA(r.Context(), req.MFAToken, req.Code, utils.GetClientIP(r), r.UserAgent())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, loginResp, http.StatusOK)
+}
+
+// SetupMFA starts enrolment for a user whose login is waiting on it.
+func (h *AuthHandler) SetupMFA(w http.ResponseWriter, r *http.Request) {
+	var req models.MFASetupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.MFAToken == "" {
+		response.Error(w, "MFA token is required", http.StatusBadRequest)
+		return
+	}
+
+	enrollment, err := h.authService.SetupMFA(r.Context(), req.MFAToken)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, enrollment, http.StatusOK)
+}
+
+// ActivateMFA confirms the enrolment SetupMFA started and finishes the login.
+func (h *AuthHandler) ActivateMFA(w http.ResponseWriter, r *http.Request) {
+	var req models.MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.MFAToken == "" || req.Code == "" {
+		response.Error(w, "MFA token and code are required", http.StatusBadRequest)
+		return
+	}
+
+	loginResp, err := h.authService.ActivateMFA(r.Context(), req.MFAToken, req.Code, utils.GetClientIP(r), r.UserAgent())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, loginResp, http.StatusOK)
+}
+
+// EnrollMFA starts TOTP enrolment for the signed-in user.
+func (h *AuthHandler) EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.authService.BeginEnrollment(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, enrollment, http.StatusOK)
+}
+
+// ConfirmMFA turns on MFA with a code from the authenticator and returns the
+// recovery codes.
+func (h *AuthHandler) ConfirmMFA(w http.ResponseWriter, r *http.Request) {
+	h.withCode(w, r, func(userID uuid.UUID, code string) (any, error) {
+		return h.authService.ConfirmEnrollment(r.Context(), userID, code)
+	})
+}
+
+func (h *AuthHandler) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	h.withCode(w, r, func(userID uuid.UUID, code string) (any, error) {
+		return nil, h.authService.DisableMFA(r.Context(), userID, code)
+	})
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	h.withCode(w, r, func(userID uuid.UUID, code string) (any, error) {
+		return h.authService.RegenerateRecoveryCodes(r.Context(), userID, code)
+	})
+}
+
+// withCode decodes an MFACodeRequest and runs fn for the signed-in user,
+// replying 204 when fn returns no result.
+func (h *AuthHandler) withCode(w http.ResponseWriter, r *http.Request, fn func(userID uuid.UUID, code string) (any, error)) {
+	var req models.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Code == "" {
+		response.Error(w, "Code is required", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := fn(userID, req.Code)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if result == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	response.JSON(w, result, http.StatusOK)
+}
+
+// ChangePassword replaces the caller's password and logs out their other
+// sessions.
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		response.Error(w, "Current and new password are required", http.StatusBadRequest)
+		return
+	}
+
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	err := h.authService.ChangePassword(r.Context(), claims, req.CurrentPassword, req.NewPassword, utils.GetClientIP(r), r.UserAgent())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResetPassword sets a new password with a one-time reset token.
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Token == "" || req.NewPassword == "" {
+		response.Error(w, "Token and new password are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authService.ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
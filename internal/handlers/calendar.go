@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/services"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/response"
+)
+
+// maxICalendarSize bounds an uploaded .ics file. A year of holidays is a few
+// kilobytes.
+const maxICalendarSize = 1 << 20
+
+type CalendarHandler struct {
+	calendarService *services.CalendarService
+}
+
+func NewCalendarHandler(calendarService *services.CalendarService) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+func (h *CalendarHandler) ListLocations(w http.ResponseWriter, r *http.Request) {
+	locations, err := h.calendarService.ListLocations(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, locations, http.StatusOK)
+}
+
+func (h *CalendarHandler) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	var req models.LocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	location, err := h.calendarService.CreateLocation(r.Context(), req, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, location, http.StatusCreated)
+}
+
+func (h *CalendarHandler) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	locationID, err := uuid.Parse(chi.URLParam(r, "locationID"))
+	if err != nil {
+		response.Error(w, "Invalid location ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.LocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	location, err := h.calendarService.UpdateLocation(r.Context(), locationID, req, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, location, http.StatusOK)
+}
+
+// ListHolidays lists the holidays between from and to, which default to the
+// current calendar year. With location_id only the holidays observed at that
+// location are returned.
+func (h *CalendarHandler) ListHolidays(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	year := h.calendarService.Today().Year()
+	filter := models.HolidayFilter{
+		From: time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	if v := query.Get("from"); v != "" {
+		from, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			response.Error(w, "Invalid from date format", http.StatusBadRequest)
+			return
+		}
+		filter.From = from
+	}
+
+	if v := query.Get("to"); v != "" {
+		to, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			response.Error(w, "Invalid to date format", http.StatusBadRequest)
+			return
+		}
+		filter.To = to
+	}
+
+	if v := query.Get("location_id"); v != "" {
+		locationID, err := uuid.Parse(v)
+		if err != nil {
+			response.Error(w, "Invalid location ID", http.StatusBadRequest)
+			return
+		}
+		filter.LocationID = &locationID
+	}
+
+	holidays, err := h.calendarService.ListHolidays(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, holidays, http.StatusOK)
+}
+
+func (h *CalendarHandler) CreateHoliday(w http.ResponseWriter, r *http.Request) {
+	var req models.HolidayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	holiday, err := h.calendarService.CreateHoliday(r.Context(), req, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, holiday, http.StatusCreated)
+}
+
+func (h *CalendarHandler) UpdateHoliday(w http.ResponseWriter, r *http.Request) {
+	holidayID, err := uuid.Parse(chi.URLParam(r, "holidayID"))
+	if err != nil {
+		response.Error(w, "Invalid holiday ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.HolidayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	holiday, err := h.calendarService.UpdateHoliday(r.Context(), holidayID, req, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, holiday, http.StatusOK)
+}
+
+func (h *CalendarHandler) DeleteHoliday(w http.ResponseWriter, r *http.Request) {
+	holidayID, err := uuid.Parse(chi.URLParam(r, "holidayID"))
+	if err != nil {
+		response.Error(w, "Invalid holiday ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.calendarService.DeleteHoliday(r.Context(), holidayID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImportHolidays imports the .ics file uploaded in the "file" field. The
+// "type" field sets the holiday type of every event, and the optional
+// "location_id" field limits them to one location.
+func (h *CalendarHandler) ImportHolidays(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxICalendarSize+1<<10)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		response.Error(w, "An iCalendar file of at most 1 MB is required in the \"file\" field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	holidayType := r.FormValue("type")
+	if holidayType == "" {
+		holidayType = models.HolidayTypePublic
+	}
+
+	var locationID *uuid.UUID
+	if v := r.FormValue("location_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			response.Error(w, "Invalid location ID", http.StatusBadRequest)
+			return
+		}
+		locationID = &id
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.calendarService.ImportHolidays(r.Context(), file, holidayType, locationID, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, result, http.StatusCreated)
+}
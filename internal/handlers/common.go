@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/response"
+)
+
+type CommonHandler struct {
+	periodRepo repository.AttendancePeriodRepository
+}
+
+func NewCommonHandler(periodRepo repository.AttendancePeriodRepository) *CommonHandler {
+	return &CommonHandler{
+		periodRepo: periodRepo,
+	}
+}
+
+func (h *CommonHandler) GetAttendancePeriods(w http.ResponseWriter, r *http.Request) {
+	periods, err := h.periodRepo.GetAll(r.Context())
+	if err != nil {
+		response.Error(w, "Failed to fetch attendance periods", http.StatusInternalServerError)
+		return
+	}
+
+	response.JSON(w, periods, http.StatusOK)
+}
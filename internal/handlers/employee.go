@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/services"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/response"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/utils"
+)
+
+type EmployeeHandler struct {
+	attendanceService    *services.AttendanceService
+	overtimeService      *services.OvertimeService
+	reimbursementService *services.ReimbursementService
+	payrollService       *services.PayrollService
+}
+
+func NewEmployeeHandler(
+	attendanceService *services.AttendanceService,
+	overtimeService *services.OvertimeService,
+	reimbursementService *services.ReimbursementService,
+	payrollService *services.PayrollService,
+) *EmployeeHandler {
+	return &EmployeeHandler{
+		attendanceService:    attendanceService,
+		overtimeService:      overtimeService,
+		reimbursementService: reimbursementService,
+		payrollService:       payrollService,
+	}
+}
+
+func (h *EmployeeHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	// The body is optional; the period is resolved from today's date.
+	var req models.CheckInRequest
+	if err := decodeOptionalBody(r, &req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	attendance, err := h.attendanceService.CheckIn(r.Context(), userID, req, utils.GetClientIP(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, attendance, http.StatusCreated)
+}
+
+func (h *EmployeeHandler) CheckOut(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	attendance, err := h.attendanceService.CheckOut(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, attendance, http.StatusOK)
+}
+
+func (h *EmployeeHandler) SubmitOvertime(w http.ResponseWriter, r *http.Request) {
+	var req models.SubmitOvertimeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	overtime, err := h.overtimeService.SubmitOvertime(r.Context(), userID, req, utils.GetClientIP(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, overtime, http.StatusOK)
+}
+
+func (h *EmployeeHandler) SubmitReimbursement(w http.ResponseWriter, r *http.Request) {
+	var req models.SubmitReimbursementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	reimbursement, err := h.reimbursementService.SubmitReimbursement(r.Context(), userID, req, utils.GetClientIP(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, reimbursement, http.StatusCreated)
+}
+
+func (h *EmployeeHandler) UpdateReimbursement(w http.ResponseWriter, r *http.Request) {
+	reimbursementID, err := uuid.Parse(chi.URLParam(r, "reimbursementID"))
+	if err != nil {
+		response.Error(w, "Invalid reimbursement ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.UpdateReimbursementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	reimbursement, err := h.reimbursementService.UpdateReimbursement(r.Context(), userID, reimbursementID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, reimbursement, http.StatusOK)
+}
+
+func (h *EmployeeHandler) UploadReceipt(w http.ResponseWriter, r *http.Request) {
+	reimbursementID, err := uuid.Parse(chi.URLParam(r, "reimbursementID"))
+	if err != nil {
+		response.Error(w, "Invalid reimbursement ID", http.StatusBadRequest)
+		return
+	}
+
+	// Leave headroom for the multipart envelope around the file.
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxReceiptSize+1<<20)
+	file, header, err := r.FormFile("receipt")
+	if err != nil {
+		response.Error(w, "A receipt file of at most 5 MB is required in the \"receipt\" field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, services.MaxReceiptSize+1))
+	if err != nil {
+		response.Error(w, "Failed to read receipt file", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	reimbursement, err := h.reimbursementService.UploadReceipt(r.Context(), userID, reimbursementID, header.Filename, content)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, reimbursement, http.StatusOK)
+}
+
+func (h *EmployeeHandler) GetPayslip(w http.ResponseWriter, r *http.Request) {
+	periodID, err := uuid.Parse(chi.URLParam(r, "periodID"))
+	if err != nil {
+		response.Error(w, "Invalid attendance period ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	payslip, err := h.payrollService.GetPayslip(r.Context(), userID, periodID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, payslip, http.StatusOK)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/services"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/response"
+)
+
+type ExchangeRateHandler struct {
+	rateService *services.ExchangeRateService
+}
+
+func NewExchangeRateHandler(rateService *services.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		rateService: rateService,
+	}
+}
+
+// ListRates lists exchange rates, newest first per currency pair, optionally
+// filtered by from and to currency.
+func (h *ExchangeRateHandler) ListRates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.ExchangeRateFilter{
+		FromCurrency: query.Get("from"),
+		ToCurrency:   query.Get("to"),
+	}
+
+	rates, err := h.rateService.List(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, rates, http.StatusOK)
+}
+
+func (h *ExchangeRateHandler) CreateRate(w http.ResponseWriter, r *http.Request) {
+	var req models.ExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	rate, err := h.rateService.Create(r.Context(), req, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, rate, http.StatusCreated)
+}
+
+func (h *ExchangeRateHandler) UpdateRate(w http.ResponseWriter, r *http.Request) {
+	rateID, err := uuid.Parse(chi.URLParam(r, "rateID"))
+	if err != nil {
+		response.Error(w, "Invalid exchange rate ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.ExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	rate, err := h.rateService.Update(r.Context(), rateID, req, userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, rate, http.StatusOK)
+}
+
+func (h *ExchangeRateHandler) DeleteRate(w http.ResponseWriter, r *http.Request) {
+	rateID, err := uuid.Parse(chi.URLParam(r, "rateID"))
+	if err != nil {
+		response.Error(w, "Invalid exchange rate ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.rateService.Delete(r.Context(), rateID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/middleware"
+	"github.com/jordanhimawan/payroll-mgmt/internal/services"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/response"
+)
+
+// writeError renders service AppErrors with their own status code and hides
+// everything else behind a generic 500.
+func writeError(w http.ResponseWriter, err error) {
+	var appErr *services.AppError
+	if errors.As(err, &appErr) {
+		response.Error(w, appErr.Message, appErr.Code)
+		return
+	}
+	response.Error(w, "Internal server error", http.StatusInternalServerError)
+}
+
+// currentUserID returns the authenticated user's ID, writing a 401 when the
+// request did not pass through the auth middleware.
+func currentUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, "Authentication required", http.StatusUnauthorized)
+	}
+	return userID, ok
+}
+
+// decodeOptionalBody decodes a JSON body into v, treating an empty body as
+// valid.
+func decodeOptionalBody(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
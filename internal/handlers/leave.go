@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/services"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/response"
+)
+
+type LeaveHandler struct {
+	leaveService *services.LeaveService
+}
+
+func NewLeaveHandler(leaveService *services.LeaveService) *LeaveHandler {
+	return &LeaveHandler{
+		leaveService: leaveService,
+	}
+}
+
+func (h *LeaveHandler) ListTypes(w http.ResponseWriter, r *http.Request) {
+	leaveTypes, err := h.leaveService.ListTypes(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, leaveTypes, http.StatusOK)
+}
+
+func (h *LeaveHandler) GetOwnBalances(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	h.writeBalances(w, r, userID)
+}
+
+func (h *LeaveHandler) GetUserBalances(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	h.writeBalances(w, r, userID)
+}
+
+func (h *LeaveHandler) writeBalances(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	year, ok := parseYear(w, r, h.leaveService.CurrentYear())
+	if !ok {
+		return
+	}
+
+	balances, err := h.leaveService.GetBalances(r.Context(), userID, year)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, balances, http.StatusOK)
+}
+
+func (h *LeaveHandler) SetEntitlement(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.SetLeaveEntitlementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	setBy, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	entitlement, err := h.leaveService.SetEntitlement(r.Context(), userID, chi.URLParam(r, "leaveType"), req, setBy)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, entitlement, http.StatusOK)
+}
+
+func (h *LeaveHandler) SubmitRequest(w http.ResponseWriter, r *http.Request) {
+	var req models.SubmitLeaveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := h.leaveService.SubmitRequest(r.Context(), userID, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, request, http.StatusCreated)
+}
+
+func (h *LeaveHandler) ListOwnRequests(w http.ResponseWriter, r *http.Request) {
+	year, ok := parseYear(w, r, h.leaveService.CurrentYear())
+	if !ok {
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	requests, err := h.leaveService.ListOwnRequests(r.Context(), userID, year)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, requests, http.StatusOK)
+}
+
+func (h *LeaveHandler) CancelRequest(w http.ResponseWriter, r *http.Request) {
+	requestID, err := uuid.Parse(chi.URLParam(r, "leaveRequestID"))
+	if err != nil {
+		response.Error(w, "Invalid leave request ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := h.leaveService.CancelRequest(r.Context(), userID, requestID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, request, http.StatusOK)
+}
+
+func (h *LeaveHandler) GetPendingRequests(w http.ResponseWriter, r *http.Request) {
+	managerID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	requests, err := h.leaveService.GetPendingForManager(r.Context(), managerID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, requests, http.StatusOK)
+}
+
+func (h *LeaveHandler) ApproveRequest(w http.ResponseWriter, r *http.Request) {
+	h.reviewRequest(w, r, true)
+}
+
+func (h *LeaveHandler) RejectRequest(w http.ResponseWriter, r *http.Request) {
+	h.reviewRequest(w, r, false)
+}
+
+func (h *LeaveHandler) reviewRequest(w http.ResponseWriter, r *http.Request, approve bool) {
+	requestID, err := uuid.Parse(chi.URLParam(r, "leaveRequestID"))
+	if err != nil {
+		response.Error(w, "Invalid leave request ID", http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional when approving.
+	var req models.ReviewRequest
+	if err := decodeOptionalBody(r, &req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	managerID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := h.leaveService.Review(r.Context(), managerID, requestID, approve, req.Reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, request, http.StatusOK)
+}
+
+// parseYear reads the optional "year" query parameter, defaulting to
+// currentYear.
+func parseYear(w http.ResponseWriter, r *http.Request, currentYear int) (int, bool) {
+	v := r.URL.Query().Get("year")
+	if v == "" {
+		return currentYear, true
+	}
+
+	year, err := strconv.Atoi(v)
+	if err != nil || year < 2000 || year > 9999 {
+		response.Error(w, "Invalid year", http.StatusBadRequest)
+		return 0, false
+	}
+	return year, true
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/services"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/response"
+)
+
+type ManagerHandler struct {
+	overtimeService *services.OvertimeService
+}
+
+func NewManagerHandler(overtimeService *services.OvertimeService) *ManagerHandler {
+	return &ManagerHandler{
+		overtimeService: overtimeService,
+	}
+}
+
+func (h *ManagerHandler) GetPendingOvertime(w http.ResponseWriter, r *http.Request) {
+	managerID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	overtimes, err := h.overtimeService.GetPendingForManager(r.Context(), managerID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, overtimes, http.StatusOK)
+}
+
+func (h *ManagerHandler) ApproveOvertime(w http.ResponseWriter, r *http.Request) {
+	h.reviewOvertime(w, r, true)
+}
+
+func (h *ManagerHandler) RejectOvertime(w http.ResponseWriter, r *http.Request) {
+	h.reviewOvertime(w, r, false)
+}
+
+func (h *ManagerHandler) reviewOvertime(w http.ResponseWriter, r *http.Request, approve bool) {
+	overtimeID, err := uuid.Parse(chi.URLParam(r, "overtimeID"))
+	if err != nil {
+		response.Error(w, "Invalid overtime ID", http.StatusBadRequest)
+		return
+	}
+
+	// The body is optional when approving.
+	var req models.ReviewRequest
+	if err := decodeOptionalBody(r, &req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	managerID, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	overtime, err := h.overtimeService.Review(r.Context(), managerID, overtimeID, approve, req.Reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, overtime, http.StatusOK)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// parsePageParams reads page, page_size, sort and order from the query
+// string, falling back to defaultSort when no sort key is given.
+func parsePageParams(r *http.Request, defaultSort string) (models.PageParams, error) {
+	query := r.URL.Query()
+	params := models.PageParams{
+		Page:     1,
+		PageSize: defaultPageSize,
+		SortBy:   defaultSort,
+	}
+
+	if v := query.Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return params, errors.New("page must be a positive integer")
+		}
+		params.Page = page
+	}
+
+	if v := query.Get("page_size"); v != "" {
+		pageSize, err := strconv.Atoi(v)
+		if err != nil || pageSize < 1 || pageSize > maxPageSize {
+			return params, errors.New("page_size must be between 1 and 100")
+		}
+		params.PageSize = pageSize
+	}
+
+	if v := query.Get("sort"); v != "" {
+		params.SortBy = v
+	}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		params.SortDesc = true
+	default:
+		return params, errors.New("order must be asc or desc")
+	}
+
+	return params, nil
+}
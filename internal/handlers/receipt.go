@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/middleware"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/services"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/response"
+)
+
+type ReceiptHandler struct {
+	reimbursementService *services.ReimbursementService
+}
+
+func NewReceiptHandler(reimbursementService *services.ReimbursementService) *ReceiptHandler {
+	return &ReceiptHandler{
+		reimbursementService: reimbursementService,
+	}
+}
+
+func (h *ReceiptHandler) Download(w http.ResponseWriter, r *http.Request) {
+	reimbursementID, err := uuid.Parse(chi.URLParam(r, "reimbursementID"))
+	if err != nil {
+		response.Error(w, "Invalid reimbursement ID", http.StatusBadRequest)
+		return
+	}
+
+	claims, ok := middleware.ClaimsFromContext(r.Context())
+	if !ok {
+		response.Error(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+
+	reimbursement, file, err := h.reimbursementService.OpenReceipt(
+		r.Context(), claims.UserID, reimbursementID, claims.HasPermission(models.PermReimbursementsRead),
+	)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", reimbursement.ReceiptContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(reimbursement.ReceiptSize, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": reimbursement.ReceiptFileName,
+	}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, file)
+}
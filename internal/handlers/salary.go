@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/services"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/response"
+)
+
+type SalaryHandler struct {
+	salaryService *services.SalaryService
+}
+
+func NewSalaryHandler(salaryService *services.SalaryService) *SalaryHandler {
+	return &SalaryHandler{
+		salaryService: salaryService,
+	}
+}
+
+func (h *SalaryHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.salaryService.GetHistory(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, history, http.StatusOK)
+}
+
+func (h *SalaryHandler) ScheduleChange(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.ScheduleSalaryChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	createdBy, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	change, err := h.salaryService.ScheduleChange(r.Context(), userID, req, createdBy)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, change, http.StatusCreated)
+}
+
+func (h *SalaryHandler) CancelChange(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	salaryID, err := uuid.Parse(chi.URLParam(r, "salaryID"))
+	if err != nil {
+		response.Error(w, "Invalid salary change ID", http.StatusBadRequest)
+		return
+	}
+
+	cancelledBy, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.salaryService.CancelChange(r.Context(), userID, salaryID, cancelledBy); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
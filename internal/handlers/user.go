@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/services"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/response"
+)
+
+type UserHandler struct {
+	userService *services.UserService
+}
+
+func NewUserHandler(userService *services.UserService) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+// ListUsers lists users, optionally filtered by role, status (active,
+// inactive or all), manager_id, location_id and a username search q.
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	params, err := parsePageParams(r, "username")
+	if err != nil {
+		response.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := models.UserFilter{
+		Role:   query.Get("role"),
+		Search: query.Get("q"),
+	}
+
+	switch query.Get("status") {
+	case "", "all":
+	case "active":
+		active := true
+		filter.IsActive = &active
+	case "inactive":
+		active := false
+		filter.IsActive = &active
+	default:
+		response.Error(w, "status must be one of: active, inactive, all", http.StatusBadRequest)
+		return
+	}
+
+	if v := query.Get("manager_id"); v != "" {
+		managerID, err := uuid.Parse(v)
+		if err != nil {
+			response.Error(w, "Invalid manager ID", http.StatusBadRequest)
+			return
+		}
+		filter.ManagerID = &managerID
+	}
+
+	if v := query.Get("location_id"); v != "" {
+		locationID, err := uuid.Parse(v)
+		if err != nil {
+			response.Error(w, "Invalid location ID", http.StatusBadRequest)
+			return
+		}
+		filter.LocationID = &locationID
+	}
+
+	result, err := h.userService.ListUsers(r.Context(), filter, params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, result, http.StatusOK)
+}
+
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userService.GetUser(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, user, http.StatusOK)
+}
+
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	createdBy, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.CreateUser(r.Context(), req, createdBy)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, user, http.StatusCreated)
+}
+
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	updatedBy, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.UpdateUser(r.Context(), userID, req, updatedBy)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, user, http.StatusOK)
+}
+
+func (h *UserHandler) SetPassword(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var req models.SetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	updatedBy, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.SetPassword(r.Context(), userID, req.Password, updatedBy); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// IssuePasswordReset returns a one-time password reset token for the user.
+func (h *UserHandler) IssuePasswordReset(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	createdBy, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	reset, err := h.userService.IssuePasswordReset(r.Context(), userID, createdBy)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, reset, http.StatusCreated)
+}
+
+func (h *UserHandler) Deactivate(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, false)
+}
+
+func (h *UserHandler) Reactivate(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, true)
+}
+
+func (h *UserHandler) setActive(w http.ResponseWriter, r *http.Request, active bool) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	updatedBy, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var user *models.User
+	if active {
+		user, err = h.userService.Reactivate(r.Context(), userID, updatedBy)
+	} else {
+		user, err = h.userService.Deactivate(r.Context(), userID, updatedBy)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, user, http.StatusOK)
+}
+
+// Unlock lifts a login lockout on the user's account.
+func (h *UserHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	updatedBy, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.Unlock(r.Context(), userID, updatedBy); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResetMFA turns off the user's two-factor authentication.
+func (h *UserHandler) ResetMFA(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	updatedBy, ok := currentUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.ResetMFA(r.Context(), userID, updatedBy); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetLoginHistory lists the user's login attempts, newest first. It takes
+// page and page_size.
+func (h *UserHandler) GetLoginHistory(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		response.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	params, err := parsePageParams(r, "created_at")
+	if err != nil {
+		response.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.userService.GetLoginHistory(r.Context(), userID, params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, history, http.StatusOK)
+}
@@ -0,0 +1,303 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func pkcs8PEM(t *testing.T, private any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func publicPEM(t *testing.T, public any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func ed25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return private
+}
+
+func rsaKey(t *testing.T, bits int) *rsa.PrivateKey {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return private
+}
+
+func writeKeys(t *testing.T, files map[string][]byte) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadDir(t *testing.T) {
+	current := ed25519Key(t)
+	retired := rsaKey(t, 2048)
+
+	tests := []struct {
+		name        string
+		files       map[string][]byte
+		signingKID  string
+		wantMethods []string
+		wantErr     string
+	}{
+		{
+			name: "signing key and retired public key",
+			files: map[string][]byte{
+				"2025-06.pem": pkcs8PEM(t, current),
+				"2025-01.pem": publicPEM(t, &retired.PublicKey),
+				"README.txt":  []byte("not a key"),
+			},
+			signingKID:  "2025-06",
+			wantMethods: []string{"EdDSA", "RS256"},
+		},
+		{
+			name: "PKCS#1 RSA key",
+			files: map[string][]byte{
+				"rsa.pem": pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(retired)}),
+			},
+			signingKID:  "rsa",
+			wantMethods: []string{"RS256"},
+		},
+		{
+			name:    "empty directory",
+			files:   map[string][]byte{},
+			wantErr: "no keys found",
+		},
+		{
+			name:       "unknown signing key",
+			files:      map[string][]byte{"a.pem": pkcs8PEM(t, current)},
+			signingKID: "b",
+			wantErr:    `signing key "b" not found`,
+		},
+		{
+			name:       "signing key without private half",
+			files:      map[string][]byte{"a.pem": publicPEM(t, current.Public())},
+			signingKID: "a",
+			wantErr:    `signing key "a" has no private key`,
+		},
+		{
+			name:       "short RSA key",
+			files:      map[string][]byte{"a.pem": pkcs8PEM(t, rsaKey(t, 1024))},
+			signingKID: "a",
+			wantErr:    "at least 2048 bits",
+		},
+		{
+			name:       "not PEM",
+			files:      map[string][]byte{"a.pem": []byte("garbage")},
+			signingKID: "a",
+			wantErr:    "no PEM block found",
+		},
+		{
+			name:       "unsupported PEM block",
+			files:      map[string][]byte{"a.pem": pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{0}})},
+			signingKID: "a",
+			wantErr:    `unsupported PEM block "CERTIFICATE"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := LoadDir(writeKeys(t, tt.files), tt.signingKID)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadDir error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadDir: %v", err)
+			}
+
+			if got := strings.Join(set.Methods(), ","); got != strings.Join(tt.wantMethods, ",") {
+				t.Errorf("Methods() = %s, want %s", got, strings.Join(tt.wantMethods, ","))
+			}
+		})
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	current := ed25519Key(t)
+	retired := rsaKey(t, 2048)
+
+	oldSet, err := LoadDir(writeKeys(t, map[string][]byte{"old.pem": pkcs8PEM(t, retired)}), "old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newSet, err := LoadDir(writeKeys(t, map[string][]byte{
+		"new.pem": pkcs8PEM(t, current),
+		"old.pem": publicPEM(t, &retired.PublicKey),
+	}), "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSet, err := LoadDir(writeKeys(t, map[string][]byte{"new.pem": pkcs8PEM(t, ed25519Key(t))}), "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmacSet := NewHMAC([]byte("secret"))
+
+	claims := jwt.RegisteredClaims{Subject: "user"}
+	sign := func(set *KeySet) string {
+		t.Helper()
+		token, err := set.Sign(claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	// forgedHS256 is signed with the RSA public key as an HMAC secret, the
+	// classic algorithm confusion attack.
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	forged.Header["kid"] = "old"
+	forgedHS256, err := forged.SignedString(x509.MarshalPKCS1PublicKey(&retired.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		verify *KeySet
+		wantOK bool
+	}{
+		{"signed by the current key", sign(newSet), newSet, true},
+		{"signed before a rotation", sign(oldSet), newSet, true},
+		{"HMAC", sign(hmacSet), hmacSet, true},
+		{"same kid, different key", sign(otherSet), newSet, false},
+		{"unknown kid", sign(newSet), oldSet, false},
+		{"HMAC token against key files", sign(hmacSet), newSet, false},
+		{"algorithm confusion", forgedHS256, newSet, false},
+		{"wrong HMAC secret", sign(NewHMAC([]byte("other"))), hmacSet, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jwt.ParseWithClaims(tt.token, &jwt.RegisteredClaims{}, tt.verify.Keyfunc, jwt.WithValidMethods(tt.verify.Methods()))
+			if ok := err == nil; ok != tt.wantOK {
+				t.Errorf("verified = %t (err %v), want %t", ok, err, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSignSetsKID(t *testing.T) {
+	set, err := LoadDir(writeKeys(t, map[string][]byte{"2025-06.pem": pkcs8PEM(t, ed25519Key(t))}), "2025-06")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		set     *KeySet
+		wantKID any
+	}{
+		{"key file", set, "2025-06"},
+		{"HMAC", NewHMAC([]byte("secret")), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed, err := tt.set.Sign(jwt.RegisteredClaims{Subject: "user"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, _, err := jwt.NewParser().ParseUnverified(signed, &jwt.RegisteredClaims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := token.Header["kid"]; got != tt.wantKID {
+				t.Errorf("kid = %v, want %v", got, tt.wantKID)
+			}
+		})
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	edKey := ed25519Key(t)
+	rsaPrivate := rsaKey(t, 2048)
+	set, err := LoadDir(writeKeys(t, map[string][]byte{
+		"b-ed.pem":  pkcs8PEM(t, edKey),
+		"a-rsa.pem": publicPEM(t, &rsaPrivate.PublicKey),
+	}), "b-ed")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		set  *KeySet
+		want []JWK
+	}{
+		{
+			name: "key files ordered by kid",
+			set:  set,
+			want: []JWK{
+				{Kty: "RSA", Use: "sig", Alg: "RS256", Kid: "a-rsa"},
+				{Kty: "OKP", Use: "sig", Alg: "EdDSA", Kid: "b-ed", Crv: "Ed25519"},
+			},
+		},
+		{
+			name: "HMAC secrets are not published",
+			set:  NewHMAC([]byte("secret")),
+			want: []JWK{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.set.JWKS().Keys
+			if len(got) != len(tt.want) {
+				t.Fatalf("JWKS() has %d keys, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				jwk := got[i]
+				if jwk.Kty != want.Kty || jwk.Use != want.Use || jwk.Alg != want.Alg || jwk.Kid != want.Kid || jwk.Crv != want.Crv {
+					t.Errorf("JWKS().Keys[%d] = %+v, want %+v", i, jwk, want)
+				}
+				switch jwk.Kty {
+				case "RSA":
+					if jwk.N == "" || jwk.E != "AQAB" || jwk.X != "" {
+						t.Errorf("RSA JWK = %+v, want n and e=AQAB", jwk)
+					}
+				case "OKP":
+					if jwk.X == "" || jwk.N != "" {
+						t.Errorf("OKP JWK = %+v, want x", jwk)
+					}
+				}
+			}
+		})
+	}
+}
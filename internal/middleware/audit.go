@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/jordanhimawan/payroll-mgmt/internal/audit"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/utils"
+)
+
+// AuditSource stores the request ID and client IP in the request context for
+// the audit log. It must be mounted after RealIP, so the address recorded is
+// the peer's or one a trusted proxy vouched for rather than whatever the
+// client put in a header, and after chi's RequestID. Authenticate adds the
+// actor.
+func AuditSource(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := audit.WithSource(r.Context(), audit.Source{
+			RequestID: chimiddleware.GetReqID(r.Context()),
+			IPAddress: utils.GetClientIP(r),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
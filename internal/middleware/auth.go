@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/audit"
+	"github.com/jordanhimawan/payroll-mgmt/internal/services"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/response"
+)
+
+type contextKey struct{}
+
+var claimsKey = contextKey{}
+
+type AuthMiddleware struct {
+	authService *services.AuthService
+}
+
+func NewAuthMiddleware(authService *services.AuthService) *AuthMiddleware {
+	return &AuthMiddleware{
+		authService: authService,
+	}
+}
+
+// Authenticate rejects requests without a valid Bearer token and stores the
+// token claims in the request context, recording the user as the actor for
+// the audit log.
+func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+			response.Error(w, "Missing or malformed authorization header", http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := m.authService.ValidateToken(r.Context(), token)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidToken) {
+				response.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+				return
+			}
+			response.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		ctx := audit.WithActor(WithClaims(r.Context(), claims), claims.UserID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequireRole only lets through users whose role is one of roles. It must be
+// mounted after Authenticate.
+func (m *AuthMiddleware) RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok {
+				response.Error(w, "Authentication required", http.StatusUnauthorized)
+				return
+			}
+
+			for _, role := range roles {
+				if claims.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			response.Error(w, "Insufficient permissions", http.StatusForbidden)
+		})
+	}
+}
+
+// RequirePermission only lets through users whose role grants permission. It
+// must be mounted after Authenticate.
+func (m *AuthMiddleware) RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok {
+				response.Error(w, "Authentication required", http.StatusUnauthorized)
+				return
+			}
+
+			if !claims.HasPermission(permission) {
+				response.Error(w, "Insufficient permissions", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WithClaims returns a copy of ctx carrying the authenticated user's claims.
+func WithClaims(ctx context.Context, claims *services.Claims) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// ClaimsFromContext returns the claims stored by Authenticate, if any.
+func ClaimsFromContext(ctx context.Context) (*services.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey).(*services.Claims)
+	return claims, ok && claims != nil
+}
+
+// UserIDFromContext returns the authenticated user's ID, if any.
+func UserIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return uuid.Nil, false
+	}
+	return claims.UserID, true
+}
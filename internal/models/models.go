@@ -1,22 +1,30 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/money"
 )
 
 type User struct {
-	ID           uuid.UUID  `json:"id" db:"id"`
-	Username     string     `json:"username" db:"username"`
-	PasswordHash string     `json:"-" db:"password_hash"`
-	Role         string     `json:"role" db:"role"`
-	Salary       *float64   `json:"salary,omitempty" db:"salary"`
-	IsActive     bool       `json:"is_active" db:"is_active"`
-	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
-	CreatedBy    *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
-	UpdatedBy    *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+	ID                 uuid.UUID    `json:"id" db:"id"`
+	Username           string       `json:"username" db:"username"`
+	PasswordHash       string       `json:"-" db:"password_hash"`
+	Role               string       `json:"role" db:"role"`
+	Salary             *money.Money `json:"salary,omitempty" db:"salary"` // in force today, from the salary history
+	SalaryCurrency     string       `json:"salary_currency,omitempty" db:"salary_currency"`
+	ManagerID          *uuid.UUID   `json:"manager_id,omitempty" db:"manager_id"`
+	LocationID         *uuid.UUID   `json:"location_id,omitempty" db:"location_id"`
+	IsActive           bool         `json:"is_active" db:"is_active"`
+	MFAEnabled         bool         `json:"mfa_enabled" db:"mfa_enabled"`
+	MFASecret          *string      `json:"-" db:"mfa_secret"`                              // pending until MFAEnabled
+	MustChangePassword bool         `json:"must_change_password" db:"must_change_password"` // set on passwords an admin chose
+	CreatedAt          time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time    `json:"updated_at" db:"updated_at"`
+	CreatedBy          *uuid.UUID   `json:"created_by,omitempty" db:"created_by"`
+	UpdatedBy          *uuid.UUID   `json:"updated_by,omitempty" db:"updated_by"`
 }
 
 type AttendancePeriod struct {
@@ -40,6 +48,7 @@ type Attendance struct {
 	AttendanceDate     time.Time  `json:"attendance_date" db:"attendance_date"`
 	CheckInTime        *time.Time `json:"check_in_time,omitempty" db:"check_in_time"`
 	CheckOutTime       *time.Time `json:"check_out_time,omitempty" db:"check_out_time"`
+	WorkedMinutes      *int       `json:"worked_minutes,omitempty" db:"worked_minutes"`
 	IsPresent          bool       `json:"is_present" db:"is_present"`
 	IPAddress          string     `json:"ip_address,omitempty" db:"ip_address"`
 	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
@@ -55,6 +64,10 @@ type Overtime struct {
 	OvertimeDate       time.Time  `json:"overtime_date" db:"overtime_date"`
 	HoursWorked        float64    `json:"hours_worked" db:"hours_worked"`
 	Description        string     `json:"description,omitempty" db:"description"`
+	Status             string     `json:"status" db:"status"`
+	ReviewedBy         *uuid.UUID `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt         *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	ReviewReason       string     `json:"review_reason,omitempty" db:"review_reason"`
 	IPAddress          string     `json:"ip_address,omitempty" db:"ip_address"`
 	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
@@ -63,53 +76,659 @@ type Overtime struct {
 }
 
 type Reimbursement struct {
-	ID                 uuid.UUID  `json:"id" db:"id"`
-	UserID             uuid.UUID  `json:"user_id" db:"user_id"`
-	AttendancePeriodID uuid.UUID  `json:"attendance_period_id" db:"attendance_period_id"`
-	Amount             float64    `json:"amount" db:"amount"`
-	Description        string     `json:"description" db:"description"`
-	ReceiptURL         string     `json:"receipt_url,omitempty" db:"receipt_url"`
-	Status             string     `json:"status" db:"status"`
-	IPAddress          string     `json:"ip_address,omitempty" db:"ip_address"`
-	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
-	CreatedBy          uuid.UUID  `json:"created_by" db:"created_by"`
-	UpdatedBy          *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+	ID                 uuid.UUID   `json:"id" db:"id"`
+	UserID             uuid.UUID   `json:"user_id" db:"user_id"`
+	AttendancePeriodID uuid.UUID   `json:"attendance_period_id" db:"attendance_period_id"`
+	Amount             money.Money `json:"amount" db:"amount"`
+	Currency           string      `json:"currency" db:"currency"`
+	Description        string      `json:"description" db:"description"`
+	ReceiptURL         string      `json:"receipt_url,omitempty" db:"receipt_url"`
+	ReceiptKey         string      `json:"-" db:"receipt_key"`
+	ReceiptFileName    string      `json:"receipt_file_name,omitempty" db:"receipt_file_name"`
+	ReceiptContentType string      `json:"receipt_content_type,omitempty" db:"receipt_content_type"`
+	ReceiptSize        int64       `json:"receipt_size,omitempty" db:"receipt_size"`
+	ReceiptSHA256      string      `json:"receipt_sha256,omitempty" db:"receipt_sha256"`
+	Status             string      `json:"status" db:"status"`
+	ReviewedBy         *uuid.UUID  `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt         *time.Time  `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	ReviewReason       string      `json:"review_reason,omitempty" db:"review_reason"`
+	PaidAt             *time.Time  `json:"paid_at,omitempty" db:"paid_at"`
+	IPAddress          string      `json:"ip_address,omitempty" db:"ip_address"`
+	CreatedAt          time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time   `json:"updated_at" db:"updated_at"`
+	CreatedBy          uuid.UUID   `json:"created_by" db:"created_by"`
+	UpdatedBy          *uuid.UUID  `json:"updated_by,omitempty" db:"updated_by"`
+}
+
+// SalaryChange sets a user's monthly salary from EffectiveFrom until the next
+// change.
+type SalaryChange struct {
+	ID            uuid.UUID   `json:"id" db:"id"`
+	UserID        uuid.UUID   `json:"user_id" db:"user_id"`
+	Amount        money.Money `json:"amount" db:"amount"`
+	Currency      string      `json:"currency" db:"currency"` // the user's pay currency from EffectiveFrom
+	EffectiveFrom time.Time   `json:"effective_from" db:"effective_from"`
+	Reason        string      `json:"reason,omitempty" db:"reason"`
+	CreatedAt     time.Time   `json:"created_at" db:"created_at"`
+	CreatedBy     *uuid.UUID  `json:"created_by,omitempty" db:"created_by"`
+}
+
+type LeaveType struct {
+	Code       string   `json:"code" db:"code"`
+	Name       string   `json:"name" db:"name"`
+	IsPaid     bool     `json:"is_paid" db:"is_paid"`
+	AnnualDays *float64 `json:"annual_days,omitempty" db:"annual_days"` // unlimited when nil
+	Accrual    string   `json:"accrual" db:"accrual"`
+}
+
+// LeaveEntitlement overrides a leave type's annual days for one user and year.
+type LeaveEntitlement struct {
+	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
+	LeaveType    string     `json:"leave_type" db:"leave_type"`
+	Year         int        `json:"year" db:"year"`
+	EntitledDays float64    `json:"entitled_days" db:"entitled_days"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy    uuid.UUID  `json:"created_by" db:"created_by"`
+	UpdatedBy    *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+}
+
+type LeaveRequest struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
+	LeaveType    string     `json:"leave_type" db:"leave_type"`
+	StartDate    time.Time  `json:"start_date" db:"start_date"`
+	EndDate      time.Time  `json:"end_date" db:"end_date"`
+	Days         int        `json:"days" db:"days"`
+	Reason       string     `json:"reason,omitempty" db:"reason"`
+	Status       string     `json:"status" db:"status"`
+	ReviewedBy   *uuid.UUID `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	ReviewReason string     `json:"review_reason,omitempty" db:"review_reason"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy    uuid.UUID  `json:"created_by" db:"created_by"`
+	UpdatedBy    *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+}
+
+type Payslip struct {
+	ID                 uuid.UUID   `json:"id" db:"id"`
+	UserID             uuid.UUID   `json:"user_id" db:"user_id"`
+	AttendancePeriodID uuid.UUID   `json:"attendance_period_id" db:"attendance_period_id"`
+	Currency           string      `json:"currency" db:"currency"`
+	BaseSalary         money.Money `json:"base_salary" db:"base_salary"`
+	WorkingDays        int         `json:"working_days" db:"working_days"`
+	AttendanceDays     int         `json:"attendance_days" db:"attendance_days"` // includes paid leave
+	PaidLeaveDays      int         `json:"paid_leave_days" db:"paid_leave_days"`
+	ProratedSalary     money.Money `json:"prorated_salary" db:"prorated_salary"`
+	OvertimeHours      float64     `json:"overtime_hours" db:"overtime_hours"`
+	OvertimePay        money.Money `json:"overtime_pay" db:"overtime_pay"`
+	ReimbursementTotal money.Money `json:"reimbursement_total" db:"reimbursement_total"`
+	TakeHomePay        money.Money `json:"take_home_pay" db:"take_home_pay"`
+	CreatedAt          time.Time   `json:"created_at" db:"created_at"`
+	CreatedBy          uuid.UUID   `json:"created_by" db:"created_by"`
+
+	// ExchangeRates are the rates amounts in other currencies were converted
+	// into Currency with.
+	ExchangeRates []PayslipExchangeRate `json:"exchange_rates,omitempty" db:"-"`
+
+	// ReimbursementIDs are the approved reimbursements paid out by this
+	// payslip. They are only set while processing and are not persisted.
+	ReimbursementIDs []uuid.UUID `json:"-" db:"-"`
+}
+
+// Location is an office with its own working week. Users without a location
+// follow the default one.
+// ExchangeRate values one unit of FromCurrency at Rate units of ToCurrency
+// from RateDate until the next rate for the pair.
+type ExchangeRate struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	FromCurrency string     `json:"from_currency" db:"from_currency"`
+	ToCurrency   string     `json:"to_currency" db:"to_currency"`
+	Rate         money.Rate `json:"rate" db:"rate"`
+	RateDate     time.Time  `json:"rate_date" db:"rate_date"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	UpdatedBy    *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+}
+
+// PayslipExchangeRate is a rate a payslip was converted with.
+type PayslipExchangeRate struct {
+	FromCurrency string     `json:"from_currency" db:"from_currency"`
+	ToCurrency   string     `json:"to_currency" db:"to_currency"`
+	Rate         money.Rate `json:"rate" db:"rate"`
+	RateDate     time.Time  `json:"rate_date" db:"rate_date"`
+}
+
+// RefreshToken is one link in a login session's chain of refresh tokens.
+// Only the SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	ID              uuid.UUID  `db:"id"`
+	UserID          uuid.UUID  `db:"user_id"`
+	SessionID       uuid.UUID  `db:"session_id"`
+	TokenHash       string     `db:"token_hash"`
+	AccessJTI       uuid.UUID  `db:"access_jti"`
+	AccessExpiresAt time.Time  `db:"access_expires_at"`
+	ExpiresAt       time.Time  `db:"expires_at"`
+	CreatedAt       time.Time  `db:"created_at"`
+	UsedAt          *time.Time `db:"used_at"`
+	RevokedAt       *time.Time `db:"revoked_at"`
+}
+
+// MFA challenge purposes: a challenge is either redeemed with a code, or lets
+// a user whose role requires MFA enrol before finishing login.
+const (
+	MFAPurposeVerify = "verify"
+	MFAPurposeEnroll = "enroll"
+)
+
+// MFAChallenge is the pending second step of a login.
+type MFAChallenge struct {
+	ID        uuid.UUID  `db:"id"`
+	UserID    uuid.UUID  `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	Purpose   string     `db:"purpose"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// PasswordResetToken lets a user set a new password without the old one.
+// Only the SHA-256 hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uuid.UUID  `db:"id"`
+	UserID    uuid.UUID  `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+	CreatedBy *uuid.UUID `db:"created_by"`
+}
+
+// Login throttle scopes: failed attempts are counted per username and per
+// client IP.
+const (
+	LoginScopeUsername = "username"
+	LoginScopeIP       = "ip"
+)
+
+// Login failure reasons
+const (
+	LoginFailureInvalidCredentials = "invalid_credentials"
+	LoginFailureLocked             = "locked"
+	LoginFailureInvalidMFACode     = "invalid_mfa_code"
+)
+
+// LoginHistory records one login attempt. UserID is nil when the username
+// matched no active user.
+type LoginHistory struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	UserID        *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+	Username      string     `json:"username" db:"username"`
+	Success       bool       `json:"success" db:"success"`
+	FailureReason *string    `json:"failure_reason,omitempty" db:"failure_reason"`
+	IPAddress     string     `json:"ip_address" db:"ip_address"`
+	UserAgent     string     `json:"user_agent" db:"user_agent"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// AuditLog records one insert, update or delete of a row. EntityType is the
+// table and EntityID the row's key, with the columns of composite keys joined
+// by "/". Updates carry only the columns that changed; inserts have no Before
+// and deletes no After. ActorID is nil for changes made without a signed-in
+// user.
+type AuditLog struct {
+	ID         uuid.UUID       `json:"id" db:"id"`
+	ActorID    *uuid.UUID      `json:"actor_id,omitempty" db:"actor_id"`
+	Action     string          `json:"action" db:"action"`
+	EntityType string          `json:"entity_type" db:"entity_type"`
+	EntityID   string          `json:"entity_id" db:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty" db:"before"`
+	After      json.RawMessage `json:"after,omitempty" db:"after"`
+	RequestID  string          `json:"request_id" db:"request_id"`
+	IPAddress  string          `json:"ip_address" db:"ip_address"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+type Location struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name"`
+	WeekendDays []int      `json:"weekend_days" db:"weekend_days"` // 0 = Sunday through 6 = Saturday
+	IsDefault   bool       `json:"is_default" db:"is_default"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	UpdatedBy   *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+}
+
+// Holiday is a non-working day. Holidays without a location apply everywhere.
+type Holiday struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	HolidayDate time.Time  `json:"holiday_date" db:"holiday_date"`
+	Name        string     `json:"name" db:"name"`
+	Type        string     `json:"type" db:"type"`
+	LocationID  *uuid.UUID `json:"location_id,omitempty" db:"location_id"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	CreatedBy   uuid.UUID  `json:"created_by" db:"created_by"`
+	UpdatedBy   *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
 }
 
+// User roles
+const (
+	RoleAdmin    = "admin"
+	RoleHR       = "hr"
+	RoleFinance  = "finance"
+	RoleManager  = "manager"
+	RoleAuditor  = "auditor"
+	RoleEmployee = "employee"
+)
+
+// Permissions, granted to roles through the role_permissions table
+const (
+	PermAttendancePeriodsRead   = "attendance_periods:read"
+	PermAttendancePeriodsManage = "attendance_periods:manage"
+	PermPayrollRun              = "payroll:run"
+	PermPayrollRead             = "payroll:read"
+	PermUsersRead               = "users:read"
+	PermUsersManage             = "users:manage"
+	PermAttendanceSubmit        = "attendance:submit"
+	PermOvertimeSubmit          = "overtime:submit"
+	PermOvertimeApprove         = "overtime:approve"
+	PermReimbursementsSubmit    = "reimbursements:submit"
+	PermReimbursementsRead      = "reimbursements:read"
+	PermReimbursementsApprove   = "reimbursements:approve"
+	PermPayslipsReadOwn         = "payslips:read_own"
+	PermCalendarManage          = "calendar:manage"
+	PermLeaveSubmit             = "leave:submit"
+	PermLeaveApprove            = "leave:approve"
+	PermExchangeRatesManage     = "exchange_rates:manage"
+	PermAuditLogsRead           = "audit_logs:read"
+)
+
+// Overtime statuses
+const (
+	OvertimeStatusPending  = "pending"
+	OvertimeStatusApproved = "approved"
+	OvertimeStatusRejected = "rejected"
+)
+
+// Reimbursement statuses
+const (
+	ReimbursementStatusPending  = "pending"
+	ReimbursementStatusApproved = "approved"
+	ReimbursementStatusRejected = "rejected"
+	ReimbursementStatusPaid     = "paid"
+)
+
+// Holiday types
+const (
+	HolidayTypePublic         = "public_holiday"
+	HolidayTypeCompanyClosure = "company_closure"
+)
+
+// Leave accrual schemes
+const (
+	LeaveAccrualUpfront = "upfront"
+	LeaveAccrualMonthly = "monthly"
+)
+
+// Leave request statuses
+const (
+	LeaveStatusPending   = "pending"
+	LeaveStatusApproved  = "approved"
+	LeaveStatusRejected  = "rejected"
+	LeaveStatusCancelled = "cancelled"
+)
+
 // Request DTOs
 type LoginRequest struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutRequest struct {
+	AllSessions bool `json:"all_sessions,omitempty"` // also log out every other device
+}
+
+// MFAVerifyRequest finishes a login. Code is a TOTP code or a recovery code.
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type MFASetupRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+// ResetPasswordRequest redeems a password reset token.
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
 type CreateAttendancePeriodRequest struct {
 	Name      string `json:"name" validate:"required"`
 	StartDate string `json:"start_date" validate:"required"` // YYYY-MM-DD format
 	EndDate   string `json:"end_date" validate:"required"`   // YYYY-MM-DD format
 }
 
-type SubmitAttendanceRequest struct {
-	AttendancePeriodID string `json:"attendance_period_id" validate:"required,uuid"`
-	AttendanceDate     string `json:"attendance_date" validate:"required"` // YYYY-MM-DD format
+// CheckInRequest checks the user in for today.
+type CheckInRequest struct {
+	AttendancePeriodID string `json:"attendance_period_id,omitempty" validate:"omitempty,uuid"` // resolved from the date when empty
 }
 
 type SubmitOvertimeRequest struct {
-	AttendancePeriodID string  `json:"attendance_period_id" validate:"required,uuid"`
-	OvertimeDate       string  `json:"overtime_date" validate:"required"` // YYYY-MM-DD format
-	HoursWorked        float64 `json:"hours_worked" validate:"required,min=0.1,max=3"`
+	AttendancePeriodID string  `json:"attendance_period_id,omitempty" validate:"omitempty,uuid"` // resolved from the date when empty
+	OvertimeDate       string  `json:"overtime_date" validate:"required"`                        // YYYY-MM-DD format
+	HoursWorked        float64 `json:"hours_worked" validate:"required,min=0.1,max=8"`           // at most 3 on working days
 	Description        string  `json:"description,omitempty"`
 }
 
 type SubmitReimbursementRequest struct {
-	AttendancePeriodID string  `json:"attendance_period_id" validate:"required,uuid"`
-	Amount             float64 `json:"amount" validate:"required,min=0.01"`
-	Description        string  `json:"description" validate:"required"`
-	ReceiptURL         string  `json:"receipt_url,omitempty"`
+	AttendancePeriodID string      `json:"attendance_period_id" validate:"required,uuid"`
+	Amount             money.Money `json:"amount" validate:"required"`
+	Currency           string      `json:"currency,omitempty" validate:"omitempty,iso4217"` // the home currency when empty
+	Description        string      `json:"description" validate:"required"`
+}
+
+type LocationRequest struct {
+	Name        string `json:"name" validate:"required"`
+	WeekendDays []int  `json:"weekend_days" validate:"dive,min=0,max=6"`
+}
+
+type HolidayRequest struct {
+	HolidayDate string `json:"holiday_date" validate:"required"` // YYYY-MM-DD format
+	Name        string `json:"name" validate:"required"`
+	Type        string `json:"type" validate:"required,oneof=public_holiday company_closure"`
+	LocationID  string `json:"location_id,omitempty" validate:"omitempty,uuid"` // every location when empty
+}
+
+type HolidayFilter struct {
+	From       time.Time
+	To         time.Time
+	LocationID *uuid.UUID // also matches holidays that apply everywhere
+}
+
+type CreateUserRequest struct {
+	Username       string       `json:"username" validate:"required"`
+	Password       string       `json:"password" validate:"required"`
+	Role           string       `json:"role" validate:"required"`
+	Salary         *money.Money `json:"salary,omitempty"`                                       // effective from today
+	SalaryCurrency string       `json:"salary_currency,omitempty" validate:"omitempty,iso4217"` // the home currency when empty
+	ManagerID      string       `json:"manager_id,omitempty" validate:"omitempty,uuid"`
+	LocationID     string       `json:"location_id,omitempty" validate:"omitempty,uuid"` // default location when empty
+}
+
+// UpdateUserRequest replaces the editable fields of a user. Omitted optional
+// fields are cleared. Salaries change through the salary history instead.
+type UpdateUserRequest struct {
+	Username   string `json:"username" validate:"required"`
+	Role       string `json:"role" validate:"required"`
+	ManagerID  string `json:"manager_id,omitempty" validate:"omitempty,uuid"`
+	LocationID string `json:"location_id,omitempty" validate:"omitempty,uuid"`
+}
+
+type ScheduleSalaryChangeRequest struct {
+	Amount        money.Money `json:"amount" validate:"required"`
+	Currency      string      `json:"currency,omitempty" validate:"omitempty,iso4217"` // the home currency when empty
+	EffectiveFrom string      `json:"effective_from" validate:"required"`              // YYYY-MM-DD format
+	Reason        string      `json:"reason,omitempty"`
+}
+
+type ExchangeRateRequest struct {
+	FromCurrency string     `json:"from_currency" validate:"required,iso4217"`
+	ToCurrency   string     `json:"to_currency" validate:"required,iso4217"`
+	Rate         money.Rate `json:"rate" validate:"required"`
+	RateDate     string     `json:"rate_date" validate:"required"` // YYYY-MM-DD format
+}
+
+type ExchangeRateFilter struct {
+	FromCurrency string
+	ToCurrency   string
+}
+
+type SetPasswordRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type UserFilter struct {
+	Role       string
+	IsActive   *bool
+	ManagerID  *uuid.UUID
+	LocationID *uuid.UUID
+	Search     string // case-insensitive username substring
+}
+
+// AuditLogFilter selects audit log entries. From is inclusive and To
+// exclusive; zero times leave the range open.
+type AuditLogFilter struct {
+	EntityType string
+	EntityID   string
+	ActorID    *uuid.UUID
+	From       time.Time
+	To         time.Time
+}
+
+type SubmitLeaveRequest struct {
+	LeaveType string `json:"leave_type" validate:"required"`
+	StartDate string `json:"start_date" validate:"required"` // YYYY-MM-DD format
+	EndDate   string `json:"end_date" validate:"required"`   // YYYY-MM-DD format
+	Reason    string `json:"reason,omitempty"`
+}
+
+type SetLeaveEntitlementRequest struct {
+	Year         int     `json:"year" validate:"required"`
+	EntitledDays float64 `json:"entitled_days" validate:"min=0"`
+}
+
+type PageParams struct {
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+	SortBy   string `json:"sort_by,omitempty"`
+	SortDesc bool   `json:"sort_desc,omitempty"`
+}
+
+func (p PageParams) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+type UpdateReimbursementRequest struct {
+	Amount      money.Money `json:"amount" validate:"required"`
+	Currency    string      `json:"currency,omitempty" validate:"omitempty,iso4217"` // the home currency when empty
+	Description string      `json:"description" validate:"required"`
+}
+
+type ReimbursementFilter struct {
+	Status   string
+	UserID   *uuid.UUID
+	PeriodID *uuid.UUID
+}
+
+type ReviewRequest struct {
+	Reason string `json:"reason,omitempty"` // required when rejecting
 }
 
 // Response DTOs
+// LoginResponse is returned by login and refresh. Token is the short-lived
+// access token; RefreshToken can be exchanged once for a new pair. When a
+// second factor is needed, login instead returns only MFAToken and says
+// whether the user must verify a code or enrol first.
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token                 string     `json:"token,omitempty"`
+	ExpiresAt             *time.Time `json:"expires_at,omitempty"`
+	RefreshToken          string     `json:"refresh_token,omitempty"`
+	User                  *User      `json:"user,omitempty"`
+	MFARequired           bool       `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool       `json:"mfa_enrollment_required,omitempty"`
+	MFAToken              string     `json:"mfa_token,omitempty"`
+	RecoveryCodes         []string   `json:"recovery_codes,omitempty"` // only when enrolment completes
+	// PasswordChangeRequired replaces the session when the password must be
+	// changed; PasswordResetToken sets the new one.
+	PasswordChangeRequired bool   `json:"password_change_required,omitempty"`
+	PasswordResetToken     string `json:"password_reset_token,omitempty"`
+}
+
+// PasswordResetResponse carries a reset token for an admin to hand to the
+// user. It is shown once.
+type PasswordResetResponse struct {
+	ResetToken string    `json:"reset_token"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// MFAEnrollmentResponse starts TOTP enrolment. ProvisioningURI is the
+// otpauth:// URI to show as a QR code; Secret is for manual entry.
+type MFAEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesResponse lists single-use recovery codes. They are shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type PayrollRunResponse struct {
+	Period           AttendancePeriod       `json:"period"`
+	PayslipCount     int                    `json:"payslip_count"`
+	TotalTakeHomePay map[string]money.Money `json:"total_take_home_pay"` // by currency
+	SkippedUsers     []PayrollSkippedUser   `json:"skipped_users"`
+}
+
+// PayrollSkippedUser is a user who got no payslip in a payroll run, and why.
+type PayrollSkippedUser struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Reason   string    `json:"reason"`
+}
+
+type PayslipOvertimeLine struct {
+	OvertimeDate time.Time `json:"overtime_date"`
+	HoursWorked  float64   `json:"hours_worked"`
+	Description  string    `json:"description,omitempty"`
+	Status       string    `json:"status"`
+}
+
+type PayslipReimbursementLine struct {
+	ID          uuid.UUID   `json:"id"`
+	Description string      `json:"description"`
+	Amount      money.Money `json:"amount"`
+	Currency    string      `json:"currency"`
+	Status      string      `json:"status"`
+}
+
+type PayslipResponse struct {
+	Period             AttendancePeriod           `json:"period"`
+	WorkingDays        int                        `json:"working_days"`
+	AttendanceDays     int                        `json:"attendance_days"`
+	PaidLeaveDays      int                        `json:"paid_leave_days"`
+	Currency           string                     `json:"currency"`
+	BaseSalary         money.Money                `json:"base_salary"`
+	ProratedSalary     money.Money                `json:"prorated_salary"`
+	OvertimeHours      float64                    `json:"overtime_hours"`
+	OvertimePay        money.Money                `json:"overtime_pay"`
+	Overtimes          []PayslipOvertimeLine      `json:"overtimes"`
+	Reimbursements     []PayslipReimbursementLine `json:"reimbursements"`
+	ReimbursementTotal money.Money                `json:"reimbursement_total"`
+	TakeHomePay        money.Money                `json:"take_home_pay"`
+	ExchangeRates      []PayslipExchangeRate      `json:"exchange_rates"`
+}
+
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	TotalItems int `json:"total_items"`
+	TotalPages int `json:"total_pages"`
+}
+
+func NewPagination(params PageParams, totalItems int) Pagination {
+	totalPages := 0
+	if params.PageSize > 0 {
+		totalPages = (totalItems + params.PageSize - 1) / params.PageSize
+	}
+	return Pagination{
+		Page:       params.Page,
+		PageSize:   params.PageSize,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+	}
+}
+
+type PayslipSummary struct {
+	UserID             uuid.UUID   `json:"user_id"`
+	Username           string      `json:"username"`
+	Currency           string      `json:"currency"`
+	BaseSalary         money.Money `json:"base_salary"`
+	ProratedSalary     money.Money `json:"prorated_salary"`
+	OvertimePay        money.Money `json:"overtime_pay"`
+	ReimbursementTotal money.Money `json:"reimbursement_total"`
+	TakeHomePay        money.Money `json:"take_home_pay"`
+}
+
+// PayrollTotals adds up the payslips of a period in one currency.
+type PayrollTotals struct {
+	Currency           string      `json:"currency"`
+	EmployeeCount      int         `json:"employee_count"`
+	ProratedSalary     money.Money `json:"prorated_salary"`
+	OvertimePay        money.Money `json:"overtime_pay"`
+	ReimbursementTotal money.Money `json:"reimbursement_total"`
+	TakeHomePay        money.Money `json:"take_home_pay"`
+}
+
+type PayrollSummaryResponse struct {
+	Period     AttendancePeriod `json:"period"`
+	Payslips   []PayslipSummary `json:"payslips"`
+	Totals     []PayrollTotals  `json:"totals"` // one per currency
+	Pagination Pagination       `json:"pagination"`
+}
+
+// LeaveBalance is a user's standing for one leave type and year. Pending
+// requests are reserved against the balance until they are reviewed.
+type LeaveBalance struct {
+	LeaveType     string  `json:"leave_type"`
+	Year          int     `json:"year"`
+	EntitledDays  float64 `json:"entitled_days"`
+	AccruedDays   float64 `json:"accrued_days"`
+	UsedDays      float64 `json:"used_days"`
+	PendingDays   float64 `json:"pending_days"`
+	AvailableDays float64 `json:"available_days"`
+}
+
+type HolidayImportResponse struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"` // dates that already had a holiday
+}
+
+type UserListResponse struct {
+	Users      []User     `json:"users"`
+	Pagination Pagination `json:"pagination"`
+}
+
+type LoginHistoryResponse struct {
+	Attempts   []LoginHistory `json:"attempts"`
+	Pagination Pagination     `json:"pagination"`
+}
+
+type AuditLogListResponse struct {
+	Entries    []AuditLog `json:"entries"`
+	Pagination Pagination `json:"pagination"`
+}
+
+type ReimbursementListResponse struct {
+	Reimbursements []Reimbursement `json:"reimbursements"`
+	Pagination     Pagination      `json:"pagination"`
 }
@@ -0,0 +1,11 @@
+package repository
+
+import "errors"
+
+var (
+	ErrNotFound               = errors.New("record not found")
+	ErrPeriodAlreadyProcessed = errors.New("attendance period payroll already processed")
+	ErrPeriodLocked           = errors.New("attendance period is closed for changes")
+	ErrDuplicate              = errors.New("record violates a unique constraint")
+	ErrStatusChanged          = errors.New("record status changed since it was read")
+)
@@ -11,32 +11,223 @@ import (
 type UserRepository interface {
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
-	Create(ctx context.Context, user *models.User) error
+	// GetByIDIncludingInactive is GetByID for admin flows that also need to
+	// see deactivated accounts.
+	GetByIDIncludingInactive(ctx context.Context, id uuid.UUID) (*models.User, error)
+	// GetPayableInPeriod returns the active users and the deactivated users
+	// with attendance, approved overtime, reimbursements or paid leave in the
+	// period, so leaving mid-period does not forfeit pay already earned.
+	GetPayableInPeriod(ctx context.Context, periodID uuid.UUID, start, end time.Time) ([]models.User, error)
+	List(ctx context.Context, filter models.UserFilter, params models.PageParams) ([]models.User, int, error)
+	// Create also records a non-nil Salary as effective from salaryFrom.
+	Create(ctx context.Context, user *models.User, salaryFrom time.Time) error
+	// Update saves everything but the password hash and salary.
+	Update(ctx context.Context, user *models.User) error
+	// UpdatePassword sets a new password and adds it to the password
+	// history. mustChange makes the user replace it at their next login.
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string, mustChange bool, updatedBy uuid.UUID) error
+	// RehashPassword swaps the hash of the current password for one with a
+	// different cost.
+	RehashPassword(ctx context.Context, id uuid.UUID, passwordHash string) error
+}
+
+type TokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	// UseRefreshToken marks the live, unused refresh token with the hash as
+	// used and returns it. It returns ErrNotFound if there is no such token,
+	// including when it was already used or revoked.
+	UseRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	// GetSessionIDByAccessJTI returns the session an access token was issued
+	// in.
+	GetSessionIDByAccessJTI(ctx context.Context, jti uuid.UUID) (uuid.UUID, error)
+	// RevokeSession revokes the session's refresh tokens and the access tokens
+	// issued with them.
+	RevokeSession(ctx context.Context, sessionID uuid.UUID) error
+	// RevokeUser is RevokeSession for every session of the user.
+	RevokeUser(ctx context.Context, userID uuid.UUID) error
+	IsAccessTokenRevoked(ctx context.Context, jti uuid.UUID) (bool, error)
+}
+
+type MFARepository interface {
+	// SetPendingSecret stores the secret of an enrolment that has not been
+	// confirmed yet. It fails with ErrNotFound if MFA is already enabled.
+	SetPendingSecret(ctx context.Context, userID uuid.UUID, secret string) error
+	// Enable turns on MFA with the pending secret, accepting codes after step,
+	// and replaces the user's recovery codes.
+	Enable(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes []string) error
+	// Disable turns off MFA and drops the secret and recovery codes.
+	Disable(ctx context.Context, userID uuid.UUID) error
+	// UseStep records step as the last accepted TOTP step. It returns
+	// ErrNotFound if a code from this step or a later one was already used.
+	UseStep(ctx context.Context, userID uuid.UUID, step int64) error
+	// UseRecoveryCode marks an unused recovery code as used, or returns
+	// ErrNotFound.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	CreateChallenge(ctx context.Context, challenge *models.MFAChallenge) error
+	// GetChallenge returns the unused, unexpired challenge with the hash.
+	GetChallenge(ctx context.Context, tokenHash string) (*models.MFAChallenge, error)
+	// UseChallenge marks the challenge used. It returns ErrNotFound if it
+	// already was.
+	UseChallenge(ctx context.Context, id uuid.UUID) error
+}
+
+type PasswordRepository interface {
+	// RecentHashes returns the user's last n password hashes, newest first.
+	RecentHashes(ctx context.Context, userID uuid.UUID, n int) ([]string, error)
+	// CreateResetToken stores a reset token, voiding the user's unused ones.
+	CreateResetToken(ctx context.Context, token *models.PasswordResetToken) error
+	// GetResetToken returns the unused, unexpired token with the hash.
+	GetResetToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	// ChangePassword sets a password the user chose and revokes their
+	// sessions other than keepSessionID, in one transaction.
+	ChangePassword(ctx context.Context, userID uuid.UUID, passwordHash string, keepSessionID uuid.UUID) error
+	// ResetPassword uses up a reset token, sets the new password, revokes
+	// every session and clears the username's login throttle, in one
+	// transaction. It returns ErrNotFound if the token was already used.
+	ResetPassword(ctx context.Context, tokenID, userID uuid.UUID, passwordHash, throttleSubject string) error
+}
+
+type LoginRepository interface {
+	// LockedUntil returns when the lock on a throttle subject ends, or the
+	// zero time if it is not locked.
+	LockedUntil(ctx context.Context, scope, subject string) (time.Time, error)
+	// RecordFailure counts a failed attempt and returns the count, restarting
+	// from one if the previous failure was before since.
+	RecordFailure(ctx context.Context, scope, subject string, since time.Time) (int, error)
+	Lock(ctx context.Context, scope, subject string, until time.Time) error
+	Reset(ctx context.Context, scope, subject string) error
+	CreateHistory(ctx context.Context, entry *models.LoginHistory) error
+	ListHistory(ctx context.Context, userID uuid.UUID, params models.PageParams) ([]models.LoginHistory, int, error)
+}
+
+// AuditRepository reads the audit log. Entries are written by database
+// triggers, in the same transaction as the changes they record.
+type AuditRepository interface {
+	List(ctx context.Context, filter models.AuditLogFilter, params models.PageParams) ([]models.AuditLog, int, error)
+}
+
+type SalaryRepository interface {
+	Create(ctx context.Context, change *models.SalaryChange) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.SalaryChange, error)
+	// GetByUser returns the user's salary changes, oldest first.
+	GetByUser(ctx context.Context, userID uuid.UUID) ([]models.SalaryChange, error)
+}
+
+type ExchangeRateRepository interface {
+	Create(ctx context.Context, rate *models.ExchangeRate) error
+	Update(ctx context.Context, rate *models.ExchangeRate) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ExchangeRate, error)
+	List(ctx context.Context, filter models.ExchangeRateFilter) ([]models.ExchangeRate, error)
+	// GetEffective returns the latest rate for the pair dated on or before
+	// date.
+	GetEffective(ctx context.Context, from, to string, date time.Time) (*models.ExchangeRate, error)
+}
+
+type RoleRepository interface {
+	Exists(ctx context.Context, role string) (bool, error)
+	GetPermissions(ctx context.Context, role string) ([]string, error)
 }
 
 type AttendancePeriodRepository interface {
 	Create(ctx context.Context, period *models.AttendancePeriod) error
 	GetAll(ctx context.Context) ([]models.AttendancePeriod, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.AttendancePeriod, error)
+	GetByDate(ctx context.Context, date time.Time) (*models.AttendancePeriod, error)
+	GetOverlapping(ctx context.Context, start, end time.Time) ([]models.AttendancePeriod, error)
 	Update(ctx context.Context, period *models.AttendancePeriod) error
 }
 
+type CalendarRepository interface {
+	CreateLocation(ctx context.Context, location *models.Location) error
+	UpdateLocation(ctx context.Context, location *models.Location) error
+	GetLocation(ctx context.Context, id uuid.UUID) (*models.Location, error)
+	GetDefaultLocation(ctx context.Context) (*models.Location, error)
+	ListLocations(ctx context.Context) ([]models.Location, error)
+
+	CreateHoliday(ctx context.Context, holiday *models.Holiday) error
+	// CreateHolidays inserts the holidays in one transaction, skipping dates
+	// that already have a holiday for the same location, and returns how
+	// many were inserted.
+	CreateHolidays(ctx context.Context, holidays []models.Holiday) (int, error)
+	UpdateHoliday(ctx context.Context, holiday *models.Holiday) error
+	DeleteHoliday(ctx context.Context, id uuid.UUID) error
+	GetHoliday(ctx context.Context, id uuid.UUID) (*models.Holiday, error)
+	ListHolidays(ctx context.Context, filter models.HolidayFilter) ([]models.Holiday, error)
+}
+
 type AttendanceRepository interface {
 	Create(ctx context.Context, attendance *models.Attendance) error
 	Update(ctx context.Context, attendance *models.Attendance) error
 	GetByUserAndDate(ctx context.Context, userID uuid.UUID, date time.Time) (*models.Attendance, error)
+	GetLatestByUser(ctx context.Context, userID uuid.UUID) (*models.Attendance, error)
 	GetByUserAndPeriod(ctx context.Context, userID, periodID uuid.UUID) ([]models.Attendance, error)
 }
 
 type OvertimeRepository interface {
 	Create(ctx context.Context, overtime *models.Overtime) error
-	Update(ctx context.Context, overtime *models.Overtime) error
+	// Update saves overtime if its status is still fromStatus, and returns
+	// ErrStatusChanged otherwise.
+	Update(ctx context.Context, overtime *models.Overtime, fromStatus string) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Overtime, error)
+	GetPendingByManager(ctx context.Context, managerID uuid.UUID) ([]models.Overtime, error)
 	GetByUserAndDate(ctx context.Context, userID uuid.UUID, date time.Time) (*models.Overtime, error)
 	GetByUserAndPeriod(ctx context.Context, userID, periodID uuid.UUID) ([]models.Overtime, error)
 }
 
 type ReimbursementRepository interface {
 	Create(ctx context.Context, reimbursement *models.Reimbursement) error
-	Update(ctx context.Context, reimbursement *models.Reimbursement) error
+	// Update saves reimbursement if its status is still fromStatus, and
+	// returns ErrStatusChanged otherwise.
+	Update(ctx context.Context, reimbursement *models.Reimbursement, fromStatus string) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Reimbursement, error)
+	GetActiveByReceiptHash(ctx context.Context, sha256 string) (*models.Reimbursement, error)
+	List(ctx context.Context, filter models.ReimbursementFilter, params models.PageParams) ([]models.Reimbursement, int, error)
 	GetByUserAndPeriod(ctx context.Context, userID, periodID uuid.UUID) ([]models.Reimbursement, error)
 }
+
+type LeaveRepository interface {
+	GetTypes(ctx context.Context) ([]models.LeaveType, error)
+	GetType(ctx context.Context, code string) (*models.LeaveType, error)
+
+	GetEntitlement(ctx context.Context, userID uuid.UUID, leaveType string, year int) (*models.LeaveEntitlement, error)
+	UpsertEntitlement(ctx context.Context, entitlement *models.LeaveEntitlement) error
+
+	// CreateRequest stores request if check passes. The user's requests are
+	// checked and created one at a time, so check sees every request made
+	// before it.
+	CreateRequest(ctx context.Context, request *models.LeaveRequest, check func(ctx context.Context) error) error
+	UpdateRequest(ctx context.Context, request *models.LeaveRequest) error
+	GetRequestByID(ctx context.Context, id uuid.UUID) (*models.LeaveRequest, error)
+	GetRequestsByUser(ctx context.Context, userID uuid.UUID, year int) ([]models.LeaveRequest, error)
+	GetPendingRequestsByManager(ctx context.Context, managerID uuid.UUID) ([]models.LeaveRequest, error)
+	// GetActiveRequestsBetween returns the user's pending and approved
+	// requests that overlap start to end.
+	GetActiveRequestsBetween(ctx context.Context, userID uuid.UUID, start, end time.Time) ([]models.LeaveRequest, error)
+	// GetApprovedPaidRequestsBetween returns the user's approved requests of
+	// paid leave types that overlap start to end.
+	GetApprovedPaidRequestsBetween(ctx context.Context, userID uuid.UUID, start, end time.Time) ([]models.LeaveRequest, error)
+	// GetUsage sums the days of the user's approved and pending requests of a
+	// leave type in a year.
+	GetUsage(ctx context.Context, userID uuid.UUID, leaveType string, year int) (approved, pending int, err error)
+}
+
+type PayslipRepository interface {
+	// ProcessPeriod locks the period, calls compute for its payslips, stores
+	// them, marks the reimbursements they include as paid and flags the
+	// period as processed, all in a single transaction. Writes to the
+	// period's attendance, overtime and reimbursements, and to salaries and
+	// leave, wait until it is done, so the inputs compute reads cannot change
+	// before the payslips are stored. compute must query with the context it
+	// is given, which runs repository reads in the same transaction. It
+	// returns ErrPeriodAlreadyProcessed if another run got there first.
+	ProcessPeriod(ctx context.Context, periodID, processedBy uuid.UUID, compute func(ctx context.Context) ([]models.Payslip, error)) ([]models.Payslip, error)
+	GetByUserAndPeriod(ctx context.Context, userID, periodID uuid.UUID) (*models.Payslip, error)
+	ListSummariesByPeriod(ctx context.Context, periodID uuid.UUID, params models.PageParams) ([]models.PayslipSummary, error)
+	// GetTotalsByPeriod adds up the payslips of a period, one entry per
+	// currency.
+	GetTotalsByPeriod(ctx context.Context, periodID uuid.UUID) ([]models.PayrollTotals, error)
+}
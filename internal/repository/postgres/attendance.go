@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+const attendanceColumns = `
+	id, user_id, attendance_period_id, attendance_date, check_in_time, check_out_time,
+	worked_minutes, is_present, ip_address, created_at, updated_at, created_by, updated_by
+`
+
+type attendanceRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAttendanceRepository(db *pgxpool.Pool) repository.AttendanceRepository {
+	return &attendanceRepository{db: db}
+}
+
+func scanAttendance(row pgx.Row, attendance *models.Attendance) error {
+	return row.Scan(
+		&attendance.ID, &attendance.UserID, &attendance.AttendancePeriodID,
+		&attendance.AttendanceDate, &attendance.CheckInTime, &attendance.CheckOutTime,
+		&attendance.WorkedMinutes, &attendance.IsPresent, &attendance.IPAddress, &attendance.CreatedAt,
+		&attendance.UpdatedAt, &attendance.CreatedBy, &attendance.UpdatedBy,
+	)
+}
+
+func (r *attendanceRepository) Create(ctx context.Context, attendance *models.Attendance) error {
+	query := `
+		INSERT INTO attendances (user_id, attendance_period_id, attendance_date, check_in_time, check_out_time, ip_address, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + attendanceColumns
+
+	err := scanAttendance(r.db.QueryRow(ctx, query,
+		attendance.UserID, attendance.AttendancePeriodID, attendance.AttendanceDate,
+		attendance.CheckInTime, attendance.CheckOutTime, attendance.IPAddress, attendance.CreatedBy,
+	), attendance)
+	return mapError(err)
+}
+
+func (r *attendanceRepository) Update(ctx context.Context, attendance *models.Attendance) error {
+	query := `
+		UPDATE attendances
+		SET check_in_time = $2, check_out_time = $3, worked_minutes = $4, is_present = $5,
+			updated_at = CURRENT_TIMESTAMP, updated_by = $6
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		attendance.ID, attendance.CheckInTime, attendance.CheckOutTime, attendance.WorkedMinutes,
+		attendance.IsPresent, attendance.UpdatedBy,
+	).Scan(&attendance.UpdatedAt)
+	return mapError(err)
+}
+
+func (r *attendanceRepository) GetByUserAndDate(ctx context.Context, userID uuid.UUID, date time.Time) (*models.Attendance, error) {
+	var attendance models.Attendance
+	query := `
+		SELECT ` + attendanceColumns + `
+		FROM attendances
+		WHERE user_id = $1 AND attendance_date = $2
+	`
+
+	if err := scanAttendance(r.db.QueryRow(ctx, query, userID, date), &attendance); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &attendance, nil
+}
+
+func (r *attendanceRepository) GetLatestByUser(ctx context.Context, userID uuid.UUID) (*models.Attendance, error) {
+	var attendance models.Attendance
+	query := `
+		SELECT ` + attendanceColumns + `
+		FROM attendances
+		WHERE user_id = $1
+		ORDER BY attendance_date DESC
+		LIMIT 1
+	`
+
+	if err := scanAttendance(r.db.QueryRow(ctx, query, userID), &attendance); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &attendance, nil
+}
+
+func (r *attendanceRepository) GetByUserAndPeriod(ctx context.Context, userID, periodID uuid.UUID) ([]models.Attendance, error) {
+	query := `
+		SELECT ` + attendanceColumns + `
+		FROM attendances
+		WHERE user_id = $1 AND attendance_period_id = $2
+		ORDER BY attendance_date
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attendances := []models.Attendance{}
+	for rows.Next() {
+		var attendance models.Attendance
+		if err := scanAttendance(rows, &attendance); err != nil {
+			return nil, err
+		}
+		attendances = append(attendances, attendance)
+	}
+
+	return attendances, rows.Err()
+}
@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+const attendancePeriodColumns = `
+	id, name, start_date, end_date, is_active, payroll_processed,
+	payroll_processed_at, created_at, updated_at, created_by, updated_by
+`
+
+type attendancePeriodRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAttendancePeriodRepository(db *pgxpool.Pool) repository.AttendancePeriodRepository {
+	return &attendancePeriodRepository{db: db}
+}
+
+func scanAttendancePeriod(row pgx.Row, period *models.AttendancePeriod) error {
+	return row.Scan(
+		&period.ID, &period.Name, &period.StartDate, &period.EndDate,
+		&period.IsActive, &period.PayrollProcessed, &period.PayrollProcessedAt,
+		&period.CreatedAt, &period.UpdatedAt, &period.CreatedBy, &period.UpdatedBy,
+	)
+}
+
+func (r *attendancePeriodRepository) Create(ctx context.Context, period *models.AttendancePeriod) error {
+	query := `
+		INSERT INTO attendance_periods (name, start_date, end_date, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + attendancePeriodColumns
+
+	err := scanAttendancePeriod(
+		r.db.QueryRow(ctx, query, period.Name, period.StartDate, period.EndDate, period.CreatedBy),
+		period,
+	)
+	return mapError(err)
+}
+
+func (r *attendancePeriodRepository) GetAll(ctx context.Context) ([]models.AttendancePeriod, error) {
+	query := `
+		SELECT ` + attendancePeriodColumns + `
+		FROM attendance_periods
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := []models.AttendancePeriod{}
+	for rows.Next() {
+		var period models.AttendancePeriod
+		if err := scanAttendancePeriod(rows, &period); err != nil {
+			return nil, err
+		}
+		periods = append(periods, period)
+	}
+
+	return periods, rows.Err()
+}
+
+func (r *attendancePeriodRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AttendancePeriod, error) {
+	var period models.AttendancePeriod
+	query := `
+		SELECT ` + attendancePeriodColumns + `
+		FROM attendance_periods
+		WHERE id = $1
+	`
+
+	if err := scanAttendancePeriod(r.db.QueryRow(ctx, query, id), &period); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &period, nil
+}
+
+func (r *attendancePeriodRepository) GetByDate(ctx context.Context, date time.Time) (*models.AttendancePeriod, error) {
+	var period models.AttendancePeriod
+	// Prefer an active period when several overlap the date.
+	query := `
+		SELECT ` + attendancePeriodColumns + `
+		FROM attendance_periods
+		WHERE $1 BETWEEN start_date AND end_date
+		ORDER BY is_active DESC, created_at DESC
+		LIMIT 1
+	`
+
+	if err := scanAttendancePeriod(r.db.QueryRow(ctx, query, date), &period); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &period, nil
+}
+
+func (r *attendancePeriodRepository) GetOverlapping(ctx context.Context, start, end time.Time) ([]models.AttendancePeriod, error) {
+	query := `
+		SELECT ` + attendancePeriodColumns + `
+		FROM attendance_periods
+		WHERE start_date <= $2 AND end_date >= $1
+		ORDER BY start_date
+	`
+
+	rows, err := r.db.Query(ctx, query, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := []models.AttendancePeriod{}
+	for rows.Next() {
+		var period models.AttendancePeriod
+		if err := scanAttendancePeriod(rows, &period); err != nil {
+			return nil, err
+		}
+		periods = append(periods, period)
+	}
+
+	return periods, rows.Err()
+}
+
+func (r *attendancePeriodRepository) Update(ctx context.Context, period *models.AttendancePeriod) error {
+	query := `
+		UPDATE attendance_periods
+		SET name = $2, start_date = $3, end_date = $4, is_active = $5,
+			updated_at = CURRENT_TIMESTAMP, updated_by = $6
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		period.ID, period.Name, period.StartDate, period.EndDate, period.IsActive, period.UpdatedBy,
+	).Scan(&period.UpdatedAt)
+	return mapError(err)
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+const auditLogColumns = `
+	id, actor_id, action, entity_type, entity_id, before, after,
+	request_id, ip_address, created_at
+`
+
+type auditRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditRepository(db *pgxpool.Pool) repository.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func scanAuditLog(row pgx.Row, entry *models.AuditLog) error {
+	return row.Scan(
+		&entry.ID, &entry.ActorID, &entry.Action, &entry.EntityType, &entry.EntityID,
+		&entry.Before, &entry.After, &entry.RequestID, &entry.IPAddress, &entry.CreatedAt,
+	)
+}
+
+func (r *auditRepository) List(ctx context.Context, filter models.AuditLogFilter, params models.PageParams) ([]models.AuditLog, int, error) {
+	var conditions []string
+	var args []any
+
+	if filter.EntityType != "" {
+		args = append(args, filter.EntityType)
+		conditions = append(conditions, fmt.Sprintf("entity_type = $%d", len(args)))
+	}
+	if filter.EntityID != "" {
+		args = append(args, filter.EntityID)
+		conditions = append(conditions, fmt.Sprintf("entity_id = $%d", len(args)))
+	}
+	if filter.ActorID != nil {
+		args = append(args, *filter.ActorID)
+		conditions = append(conditions, fmt.Sprintf("actor_id = $%d", len(args)))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM audit_logs " + where
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, params.PageSize, params.Offset())
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM audit_logs
+		%s
+		ORDER BY created_at DESC, id
+		LIMIT $%d OFFSET $%d
+	`, auditLogColumns, where, len(args)-1, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditLog{}
+	for rows.Next() {
+		var entry models.AuditLog
+		if err := scanAuditLog(rows, &entry); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, total, rows.Err()
+}
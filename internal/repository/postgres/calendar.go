@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+const locationColumns = `
+	id, name, weekend_days, is_default, created_at, updated_at, created_by, updated_by
+`
+
+const holidayColumns = `
+	id, holiday_date, name, type, location_id, created_at, updated_at, created_by, updated_by
+`
+
+type calendarRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCalendarRepository(db *pgxpool.Pool) repository.CalendarRepository {
+	return &calendarRepository{db: db}
+}
+
+func scanLocation(row pgx.Row, location *models.Location) error {
+	return row.Scan(
+		&location.ID, &location.Name, &location.WeekendDays, &location.IsDefault,
+		&location.CreatedAt, &location.UpdatedAt, &location.CreatedBy, &location.UpdatedBy,
+	)
+}
+
+func scanHoliday(row pgx.Row, holiday *models.Holiday) error {
+	return row.Scan(
+		&holiday.ID, &holiday.HolidayDate, &holiday.Name, &holiday.Type, &holiday.LocationID,
+		&holiday.CreatedAt, &holiday.UpdatedAt, &holiday.CreatedBy, &holiday.UpdatedBy,
+	)
+}
+
+func (r *calendarRepository) CreateLocation(ctx context.Context, location *models.Location) error {
+	query := `
+		INSERT INTO locations (name, weekend_days, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING ` + locationColumns
+
+	err := scanLocation(
+		r.db.QueryRow(ctx, query, location.Name, location.WeekendDays, location.CreatedBy),
+		location,
+	)
+	return mapError(err)
+}
+
+func (r *calendarRepository) UpdateLocation(ctx context.Context, location *models.Location) error {
+	query := `
+		UPDATE locations
+		SET name = $2, weekend_days = $3, updated_at = CURRENT_TIMESTAMP, updated_by = $4
+		WHERE id = $1
+		RETURNING ` + locationColumns
+
+	err := scanLocation(
+		r.db.QueryRow(ctx, query, location.ID, location.Name, location.WeekendDays, location.UpdatedBy),
+		location,
+	)
+	return mapError(err)
+}
+
+func (r *calendarRepository) GetLocation(ctx context.Context, id uuid.UUID) (*models.Location, error) {
+	var location models.Location
+	query := `
+		SELECT ` + locationColumns + `
+		FROM locations
+		WHERE id = $1
+	`
+
+	if err := scanLocation(conn(ctx, r.db).QueryRow(ctx, query, id), &location); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &location, nil
+}
+
+func (r *calendarRepository) GetDefaultLocation(ctx context.Context) (*models.Location, error) {
+	var location models.Location
+	query := `
+		SELECT ` + locationColumns + `
+		FROM locations
+		WHERE is_default
+	`
+
+	if err := scanLocation(conn(ctx, r.db).QueryRow(ctx, query), &location); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &location, nil
+}
+
+func (r *calendarRepository) ListLocations(ctx context.Context) ([]models.Location, error) {
+	query := `
+		SELECT ` + locationColumns + `
+		FROM locations
+		ORDER BY name
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := []models.Location{}
+	for rows.Next() {
+		var location models.Location
+		if err := scanLocation(rows, &location); err != nil {
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+
+	return locations, rows.Err()
+}
+
+func (r *calendarRepository) CreateHoliday(ctx context.Context, holiday *models.Holiday) error {
+	query := `
+		INSERT INTO holidays (holiday_date, name, type, location_id, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + holidayColumns
+
+	err := scanHoliday(
+		r.db.QueryRow(ctx, query,
+			holiday.HolidayDate, holiday.Name, holiday.Type, holiday.LocationID, holiday.CreatedBy,
+		),
+		holiday,
+	)
+	return mapError(err)
+}
+
+func (r *calendarRepository) CreateHolidays(ctx context.Context, holidays []models.Holiday) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO holidays (holiday_date, name, type, location_id, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT DO NOTHING
+	`
+
+	inserted := 0
+	for i := range holidays {
+		h := &holidays[i]
+		tag, err := tx.Exec(ctx, query, h.HolidayDate, h.Name, h.Type, h.LocationID, h.CreatedBy)
+		if err != nil {
+			return 0, mapError(err)
+		}
+		inserted += int(tag.RowsAffected())
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+
+	return inserted, nil
+}
+
+func (r *calendarRepository) UpdateHoliday(ctx context.Context, holiday *models.Holiday) error {
+	query := `
+		UPDATE holidays
+		SET holiday_date = $2, name = $3, type = $4, location_id = $5,
+			updated_at = CURRENT_TIMESTAMP, updated_by = $6
+		WHERE id = $1
+		RETURNING ` + holidayColumns
+
+	err := scanHoliday(
+		r.db.QueryRow(ctx, query,
+			holiday.ID, holiday.HolidayDate, holiday.Name, holiday.Type, holiday.LocationID, holiday.UpdatedBy,
+		),
+		holiday,
+	)
+	return mapError(err)
+}
+
+func (r *calendarRepository) DeleteHoliday(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM holidays WHERE id = $1`, id)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *calendarRepository) GetHoliday(ctx context.Context, id uuid.UUID) (*models.Holiday, error) {
+	var holiday models.Holiday
+	query := `
+		SELECT ` + holidayColumns + `
+		FROM holidays
+		WHERE id = $1
+	`
+
+	if err := scanHoliday(r.db.QueryRow(ctx, query, id), &holiday); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &holiday, nil
+}
+
+func (r *calendarRepository) ListHolidays(ctx context.Context, filter models.HolidayFilter) ([]models.Holiday, error) {
+	query := `
+		SELECT ` + holidayColumns + `
+		FROM holidays
+		WHERE holiday_date BETWEEN $1 AND $2
+		  AND ($3::UUID IS NULL OR location_id IS NULL OR location_id = $3)
+		ORDER BY holiday_date, location_id NULLS FIRST
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, filter.From, filter.To, filter.LocationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holidays := []models.Holiday{}
+	for rows.Next() {
+		var holiday models.Holiday
+		if err := scanHoliday(rows, &holiday); err != nil {
+			return nil, err
+		}
+		holidays = append(holidays, holiday)
+	}
+
+	return holidays, rows.Err()
+}
@@ -0,0 +1,13 @@
+package postgres
+
+import "strings"
+
+// prefixColumns qualifies a comma-separated column list with a table alias,
+// for reusing the *Columns constants in joins.
+func prefixColumns(alias, columns string) string {
+	fields := strings.Split(columns, ",")
+	for i, field := range fields {
+		fields[i] = alias + "." + strings.TrimSpace(field)
+	}
+	return strings.Join(fields, ", ")
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+// periodLockedCode is the SQLSTATE raised by the assert_attendance_period_open
+// trigger when a write targets a processed or inactive period.
+const periodLockedCode = "PL001"
+
+const uniqueViolationCode = "23505"
+
+// mapError translates driver errors into the repository's sentinel errors.
+func mapError(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case periodLockedCode:
+			return repository.ErrPeriodLocked
+		case uniqueViolationCode:
+			return repository.ErrDuplicate
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+const exchangeRateColumns = `
+	id, from_currency, to_currency, rate, rate_date, created_at, updated_at, created_by, updated_by
+`
+
+type exchangeRateRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewExchangeRateRepository(db *pgxpool.Pool) repository.ExchangeRateRepository {
+	return &exchangeRateRepository{db: db}
+}
+
+func scanExchangeRate(row pgx.Row, rate *models.ExchangeRate) error {
+	return row.Scan(
+		&rate.ID, &rate.FromCurrency, &rate.ToCurrency, &rate.Rate, &rate.RateDate,
+		&rate.CreatedAt, &rate.UpdatedAt, &rate.CreatedBy, &rate.UpdatedBy,
+	)
+}
+
+func (r *exchangeRateRepository) Create(ctx context.Context, rate *models.ExchangeRate) error {
+	query := `
+		INSERT INTO exchange_rates (from_currency, to_currency, rate, rate_date, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + exchangeRateColumns
+
+	err := scanExchangeRate(r.db.QueryRow(ctx, query,
+		rate.FromCurrency, rate.ToCurrency, rate.Rate, rate.RateDate, rate.CreatedBy,
+	), rate)
+	return mapError(err)
+}
+
+func (r *exchangeRateRepository) Update(ctx context.Context, rate *models.ExchangeRate) error {
+	query := `
+		UPDATE exchange_rates
+		SET from_currency = $2, to_currency = $3, rate = $4, rate_date = $5,
+			updated_at = CURRENT_TIMESTAMP, updated_by = $6
+		WHERE id = $1
+		RETURNING ` + exchangeRateColumns
+
+	err := scanExchangeRate(r.db.QueryRow(ctx, query,
+		rate.ID, rate.FromCurrency, rate.ToCurrency, rate.Rate, rate.RateDate, rate.UpdatedBy,
+	), rate)
+	return mapError(err)
+}
+
+func (r *exchangeRateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM exchange_rates WHERE id = $1`, id)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *exchangeRateRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	query := `
+		SELECT ` + exchangeRateColumns + `
+		FROM exchange_rates
+		WHERE id = $1
+	`
+
+	if err := scanExchangeRate(r.db.QueryRow(ctx, query, id), &rate); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &rate, nil
+}
+
+func (r *exchangeRateRepository) List(ctx context.Context, filter models.ExchangeRateFilter) ([]models.ExchangeRate, error) {
+	query := `
+		SELECT ` + exchangeRateColumns + `
+		FROM exchange_rates
+		WHERE ($1::TEXT = '' OR from_currency = $1::TEXT)
+		  AND ($2::TEXT = '' OR to_currency = $2::TEXT)
+		ORDER BY from_currency, to_currency, rate_date DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, filter.FromCurrency, filter.ToCurrency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []models.ExchangeRate{}
+	for rows.Next() {
+		var rate models.ExchangeRate
+		if err := scanExchangeRate(rows, &rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+func (r *exchangeRateRepository) GetEffective(ctx context.Context, from, to string, date time.Time) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	query := `
+		SELECT ` + exchangeRateColumns + `
+		FROM exchange_rates
+		WHERE from_currency = $1 AND to_currency = $2 AND rate_date <= $3
+		ORDER BY rate_date DESC
+		LIMIT 1
+	`
+
+	if err := scanExchangeRate(conn(ctx, r.db).QueryRow(ctx, query, from, to, date), &rate); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &rate, nil
+}
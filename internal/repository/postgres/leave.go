@@ -0,0 +1,272 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+const leaveTypeColumns = `code, name, is_paid, annual_days, accrual`
+
+const leaveEntitlementColumns = `
+	user_id, leave_type, year, entitled_days, created_at, updated_at, created_by, updated_by
+`
+
+const leaveRequestColumns = `
+	id, user_id, leave_type, start_date, end_date, days, reason,
+	status, reviewed_by, reviewed_at, review_reason,
+	created_at, updated_at, created_by, updated_by
+`
+
+type leaveRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLeaveRepository(db *pgxpool.Pool) repository.LeaveRepository {
+	return &leaveRepository{db: db}
+}
+
+func scanLeaveType(row pgx.Row, leaveType *models.LeaveType) error {
+	return row.Scan(
+		&leaveType.Code, &leaveType.Name, &leaveType.IsPaid, &leaveType.AnnualDays, &leaveType.Accrual,
+	)
+}
+
+func scanLeaveEntitlement(row pgx.Row, entitlement *models.LeaveEntitlement) error {
+	return row.Scan(
+		&entitlement.UserID, &entitlement.LeaveType, &entitlement.Year, &entitlement.EntitledDays,
+		&entitlement.CreatedAt, &entitlement.UpdatedAt, &entitlement.CreatedBy, &entitlement.UpdatedBy,
+	)
+}
+
+func scanLeaveRequest(row pgx.Row, request *models.LeaveRequest) error {
+	return row.Scan(
+		&request.ID, &request.UserID, &request.LeaveType, &request.StartDate, &request.EndDate,
+		&request.Days, &request.Reason, &request.Status, &request.ReviewedBy, &request.ReviewedAt,
+		&request.ReviewReason, &request.CreatedAt, &request.UpdatedAt, &request.CreatedBy, &request.UpdatedBy,
+	)
+}
+
+func (r *leaveRepository) GetTypes(ctx context.Context) ([]models.LeaveType, error) {
+	query := `
+		SELECT ` + leaveTypeColumns + `
+		FROM leave_types
+		ORDER BY code
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leaveTypes := []models.LeaveType{}
+	for rows.Next() {
+		var leaveType models.LeaveType
+		if err := scanLeaveType(rows, &leaveType); err != nil {
+			return nil, err
+		}
+		leaveTypes = append(leaveTypes, leaveType)
+	}
+
+	return leaveTypes, rows.Err()
+}
+
+func (r *leaveRepository) GetType(ctx context.Context, code string) (*models.LeaveType, error) {
+	var leaveType models.LeaveType
+	query := `
+		SELECT ` + leaveTypeColumns + `
+		FROM leave_types
+		WHERE code = $1
+	`
+
+	if err := scanLeaveType(r.db.QueryRow(ctx, query, code), &leaveType); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &leaveType, nil
+}
+
+func (r *leaveRepository) GetEntitlement(ctx context.Context, userID uuid.UUID, leaveType string, year int) (*models.LeaveEntitlement, error) {
+	var entitlement models.LeaveEntitlement
+	query := `
+		SELECT ` + leaveEntitlementColumns + `
+		FROM leave_entitlements
+		WHERE user_id = $1 AND leave_type = $2 AND year = $3
+	`
+
+	if err := scanLeaveEntitlement(r.db.QueryRow(ctx, query, userID, leaveType, year), &entitlement); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &entitlement, nil
+}
+
+func (r *leaveRepository) UpsertEntitlement(ctx context.Context, entitlement *models.LeaveEntitlement) error {
+	query := `
+		INSERT INTO leave_entitlements (user_id, leave_type, year, entitled_days, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, leave_type, year) DO UPDATE
+		SET entitled_days = EXCLUDED.entitled_days,
+			updated_at = CURRENT_TIMESTAMP,
+			updated_by = EXCLUDED.created_by
+		RETURNING ` + leaveEntitlementColumns
+
+	err := scanLeaveEntitlement(r.db.QueryRow(ctx, query,
+		entitlement.UserID, entitlement.LeaveType, entitlement.Year, entitlement.EntitledDays, entitlement.CreatedBy,
+	), entitlement)
+	return mapError(err)
+}
+
+func (r *leaveRepository) CreateRequest(ctx context.Context, request *models.LeaveRequest, check func(ctx context.Context) error) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the user row makes concurrent requests from the same user wait
+	// here until the one ahead has committed, so check sees it. NO KEY UPDATE
+	// leaves other tables free to reference the user meanwhile.
+	_, err = tx.Exec(ctx, `SELECT 1 FROM users WHERE id = $1 FOR NO KEY UPDATE`, request.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := check(ctx); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO leave_requests (user_id, leave_type, start_date, end_date, days, reason, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + leaveRequestColumns
+
+	err = scanLeaveRequest(tx.QueryRow(ctx, query,
+		request.UserID, request.LeaveType, request.StartDate, request.EndDate,
+		request.Days, request.Reason, request.CreatedBy,
+	), request)
+	if err != nil {
+		return mapError(err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *leaveRepository) UpdateRequest(ctx context.Context, request *models.LeaveRequest) error {
+	query := `
+		UPDATE leave_requests
+		SET status = $2, reviewed_by = $3, reviewed_at = $4, review_reason = $5,
+			updated_at = CURRENT_TIMESTAMP, updated_by = $6
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		request.ID, request.Status, request.ReviewedBy, request.ReviewedAt, request.ReviewReason, request.UpdatedBy,
+	).Scan(&request.UpdatedAt)
+	return mapError(err)
+}
+
+func (r *leaveRepository) GetRequestByID(ctx context.Context, id uuid.UUID) (*models.LeaveRequest, error) {
+	var request models.LeaveRequest
+	query := `
+		SELECT ` + leaveRequestColumns + `
+		FROM leave_requests
+		WHERE id = $1
+	`
+
+	if err := scanLeaveRequest(r.db.QueryRow(ctx, query, id), &request); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &request, nil
+}
+
+func (r *leaveRepository) GetRequestsByUser(ctx context.Context, userID uuid.UUID, year int) ([]models.LeaveRequest, error) {
+	query := `
+		SELECT ` + leaveRequestColumns + `
+		FROM leave_requests
+		WHERE user_id = $1 AND EXTRACT(YEAR FROM start_date) = $2
+		ORDER BY start_date DESC, created_at DESC
+	`
+
+	return r.queryRequests(ctx, query, userID, year)
+}
+
+func (r *leaveRepository) GetPendingRequestsByManager(ctx context.Context, managerID uuid.UUID) ([]models.LeaveRequest, error) {
+	query := `
+		SELECT ` + prefixColumns("l", leaveRequestColumns) + `
+		FROM leave_requests l
+		JOIN users u ON u.id = l.user_id
+		WHERE u.manager_id = $1 AND l.status = 'pending'
+		ORDER BY l.start_date, l.created_at
+	`
+
+	return r.queryRequests(ctx, query, managerID)
+}
+
+func (r *leaveRepository) GetActiveRequestsBetween(ctx context.Context, userID uuid.UUID, start, end time.Time) ([]models.LeaveRequest, error) {
+	query := `
+		SELECT ` + leaveRequestColumns + `
+		FROM leave_requests
+		WHERE user_id = $1 AND status IN ('pending', 'approved')
+		  AND start_date <= $3 AND end_date >= $2
+		ORDER BY start_date
+	`
+
+	return r.queryRequests(ctx, query, userID, start, end)
+}
+
+func (r *leaveRepository) GetApprovedPaidRequestsBetween(ctx context.Context, userID uuid.UUID, start, end time.Time) ([]models.LeaveRequest, error) {
+	query := `
+		SELECT ` + prefixColumns("l", leaveRequestColumns) + `
+		FROM leave_requests l
+		JOIN leave_types t ON t.code = l.leave_type
+		WHERE l.user_id = $1 AND l.status = 'approved' AND t.is_paid
+		  AND l.start_date <= $3 AND l.end_date >= $2
+		ORDER BY l.start_date
+	`
+
+	return r.queryRequests(ctx, query, userID, start, end)
+}
+
+func (r *leaveRepository) GetUsage(ctx context.Context, userID uuid.UUID, leaveType string, year int) (int, int, error) {
+	var approved, pending int
+	query := `
+		SELECT COALESCE(SUM(days) FILTER (WHERE status = 'approved'), 0),
+			   COALESCE(SUM(days) FILTER (WHERE status = 'pending'), 0)
+		FROM leave_requests
+		WHERE user_id = $1 AND leave_type = $2 AND EXTRACT(YEAR FROM start_date) = $3
+	`
+
+	if err := r.db.QueryRow(ctx, query, userID, leaveType, year).Scan(&approved, &pending); err != nil {
+		return 0, 0, err
+	}
+
+	return approved, pending, nil
+}
+
+func (r *leaveRepository) queryRequests(ctx context.Context, query string, args ...any) ([]models.LeaveRequest, error) {
+	rows, err := conn(ctx, r.db).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []models.LeaveRequest{}
+	for rows.Next() {
+		var request models.LeaveRequest
+		if err := scanLeaveRequest(rows, &request); err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+
+	return requests, rows.Err()
+}
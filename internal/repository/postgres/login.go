@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+const loginHistoryColumns = `
+	id, user_id, username, success, failure_reason, ip_address, user_agent, created_at
+`
+
+type loginRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLoginRepository(db *pgxpool.Pool) repository.LoginRepository {
+	return &loginRepository{db: db}
+}
+
+func scanLoginHistory(row pgx.Row, entry *models.LoginHistory) error {
+	return row.Scan(
+		&entry.ID, &entry.UserID, &entry.Username, &entry.Success, &entry.FailureReason,
+		&entry.IPAddress, &entry.UserAgent, &entry.CreatedAt,
+	)
+}
+
+func (r *loginRepository) LockedUntil(ctx context.Context, scope, subject string) (time.Time, error) {
+	var lockedUntil *time.Time
+	err := r.db.QueryRow(ctx, `
+		SELECT locked_until
+		FROM login_throttles
+		WHERE scope = $1 AND subject = $2 AND locked_until > CURRENT_TIMESTAMP
+	`, scope, subject).Scan(&lockedUntil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return *lockedUntil, nil
+}
+
+func (r *loginRepository) RecordFailure(ctx context.Context, scope, subject string, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO login_throttles (scope, subject, failed_count, last_failed_at)
+		VALUES ($1, $2, 1, CURRENT_TIMESTAMP)
+		ON CONFLICT (scope, subject) DO UPDATE
+		SET failed_count = CASE
+				WHEN login_throttles.last_failed_at < $3 THEN 1
+				ELSE login_throttles.failed_count + 1
+			END,
+			last_failed_at = CURRENT_TIMESTAMP
+		RETURNING failed_count
+	`, scope, subject, since).Scan(&count)
+	return count, err
+}
+
+func (r *loginRepository) Lock(ctx context.Context, scope, subject string, until time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE login_throttles
+		SET locked_until = $3
+		WHERE scope = $1 AND subject = $2
+	`, scope, subject, until)
+	return err
+}
+
+func (r *loginRepository) Reset(ctx context.Context, scope, subject string) error {
+	_, err := r.db.Exec(ctx, `
+		DELETE FROM login_throttles WHERE scope = $1 AND subject = $2
+	`, scope, subject)
+	return err
+}
+
+func (r *loginRepository) CreateHistory(ctx context.Context, entry *models.LoginHistory) error {
+	query := `
+		INSERT INTO login_history (user_id, username, success, failure_reason, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + loginHistoryColumns
+
+	err := scanLoginHistory(r.db.QueryRow(ctx, query,
+		entry.UserID, entry.Username, entry.Success, entry.FailureReason, entry.IPAddress, entry.UserAgent,
+	), entry)
+	return mapError(err)
+}
+
+func (r *loginRepository) ListHistory(ctx context.Context, userID uuid.UUID, params models.PageParams) ([]models.LoginHistory, int, error) {
+	var total int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM login_history WHERE user_id = $1
+	`, userID).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT ` + loginHistoryColumns + `
+		FROM login_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, params.PageSize, params.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []models.LoginHistory{}
+	for rows.Next() {
+		var entry models.LoginHistory
+		if err := scanLoginHistory(rows, &entry); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, total, rows.Err()
+}
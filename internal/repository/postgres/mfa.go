@@ -0,0 +1,192 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+const mfaChallengeColumns = `
+	id, user_id, token_hash, purpose, expires_at, used_at, created_at
+`
+
+type mfaRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMFARepository(db *pgxpool.Pool) repository.MFARepository {
+	return &mfaRepository{db: db}
+}
+
+func scanMFAChallenge(row pgx.Row, challenge *models.MFAChallenge) error {
+	return row.Scan(
+		&challenge.ID, &challenge.UserID, &challenge.TokenHash, &challenge.Purpose,
+		&challenge.ExpiresAt, &challenge.UsedAt, &challenge.CreatedAt,
+	)
+}
+
+func (r *mfaRepository) SetPendingSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE users
+		SET mfa_secret = $2, mfa_last_step = NULL
+		WHERE id = $1 AND mfa_enabled = false
+	`, userID, secret)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *mfaRepository) Enable(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE users
+		SET mfa_enabled = true, mfa_enabled_at = CURRENT_TIMESTAMP, mfa_last_step = $2
+		WHERE id = $1 AND mfa_enabled = false AND mfa_secret IS NOT NULL
+	`, userID, step)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *mfaRepository) Disable(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE users
+		SET mfa_enabled = false, mfa_secret = NULL, mfa_last_step = NULL, mfa_enabled_at = NULL
+		WHERE id = $1
+	`, userID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *mfaRepository) UseStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE users
+		SET mfa_last_step = $2
+		WHERE id = $1 AND (mfa_last_step IS NULL OR mfa_last_step < $2)
+	`, userID, step)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *mfaRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE mfa_recovery_codes
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, codeHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *mfaRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID uuid.UUID, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO mfa_recovery_codes (user_id, code_hash)
+		SELECT $1, unnest($2::TEXT[])
+	`, userID, codeHashes)
+	return mapError(err)
+}
+
+func (r *mfaRepository) CreateChallenge(ctx context.Context, challenge *models.MFAChallenge) error {
+	query := `
+		INSERT INTO mfa_challenges (user_id, token_hash, purpose, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + mfaChallengeColumns
+
+	err := scanMFAChallenge(r.db.QueryRow(ctx, query,
+		challenge.UserID, challenge.TokenHash, challenge.Purpose, challenge.ExpiresAt,
+	), challenge)
+	return mapError(err)
+}
+
+func (r *mfaRepository) GetChallenge(ctx context.Context, tokenHash string) (*models.MFAChallenge, error) {
+	var challenge models.MFAChallenge
+	query := `
+		SELECT ` + mfaChallengeColumns + `
+		FROM mfa_challenges
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+	`
+
+	if err := scanMFAChallenge(r.db.QueryRow(ctx, query, tokenHash), &challenge); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &challenge, nil
+}
+
+func (r *mfaRepository) UseChallenge(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE mfa_challenges
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND used_at IS NULL
+	`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+const overtimeColumns = `
+	id, user_id, attendance_period_id, overtime_date, hours_worked, description,
+	status, reviewed_by, reviewed_at, review_reason,
+	ip_address, created_at, updated_at, created_by, updated_by
+`
+
+type overtimeRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOvertimeRepository(db *pgxpool.Pool) repository.OvertimeRepository {
+	return &overtimeRepository{db: db}
+}
+
+func scanOvertime(row pgx.Row, overtime *models.Overtime) error {
+	return row.Scan(
+		&overtime.ID, &overtime.UserID, &overtime.AttendancePeriodID,
+		&overtime.OvertimeDate, &overtime.HoursWorked, &overtime.Description,
+		&overtime.Status, &overtime.ReviewedBy, &overtime.ReviewedAt, &overtime.ReviewReason,
+		&overtime.IPAddress, &overtime.CreatedAt, &overtime.UpdatedAt,
+		&overtime.CreatedBy, &overtime.UpdatedBy,
+	)
+}
+
+func (r *overtimeRepository) Create(ctx context.Context, overtime *models.Overtime) error {
+	query := `
+		INSERT INTO overtimes (user_id, attendance_period_id, overtime_date, hours_worked, description, ip_address, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + overtimeColumns
+
+	err := scanOvertime(r.db.QueryRow(ctx, query,
+		overtime.UserID, overtime.AttendancePeriodID, overtime.OvertimeDate,
+		overtime.HoursWorked, overtime.Description, overtime.IPAddress, overtime.CreatedBy,
+	), overtime)
+	return mapError(err)
+}
+
+func (r *overtimeRepository) Update(ctx context.Context, overtime *models.Overtime, fromStatus string) error {
+	query := `
+		UPDATE overtimes
+		SET hours_worked = $2, description = $3, status = $4,
+			reviewed_by = $5, reviewed_at = $6, review_reason = $7,
+			updated_at = CURRENT_TIMESTAMP, updated_by = $8
+		WHERE id = $1 AND status = $9
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		overtime.ID, overtime.HoursWorked, overtime.Description, overtime.Status,
+		overtime.ReviewedBy, overtime.ReviewedAt, overtime.ReviewReason, overtime.UpdatedBy, fromStatus,
+	).Scan(&overtime.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.ErrStatusChanged
+	}
+	return mapError(err)
+}
+
+func (r *overtimeRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Overtime, error) {
+	var overtime models.Overtime
+	query := `
+		SELECT ` + overtimeColumns + `
+		FROM overtimes
+		WHERE id = $1
+	`
+
+	if err := scanOvertime(r.db.QueryRow(ctx, query, id), &overtime); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &overtime, nil
+}
+
+func (r *overtimeRepository) GetByUserAndDate(ctx context.Context, userID uuid.UUID, date time.Time) (*models.Overtime, error) {
+	var overtime models.Overtime
+	query := `
+		SELECT ` + overtimeColumns + `
+		FROM overtimes
+		WHERE user_id = $1 AND overtime_date = $2
+	`
+
+	if err := scanOvertime(r.db.QueryRow(ctx, query, userID, date), &overtime); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &overtime, nil
+}
+
+func (r *overtimeRepository) GetByUserAndPeriod(ctx context.Context, userID, periodID uuid.UUID) ([]models.Overtime, error) {
+	query := `
+		SELECT ` + overtimeColumns + `
+		FROM overtimes
+		WHERE user_id = $1 AND attendance_period_id = $2
+		ORDER BY overtime_date
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overtimes := []models.Overtime{}
+	for rows.Next() {
+		var overtime models.Overtime
+		if err := scanOvertime(rows, &overtime); err != nil {
+			return nil, err
+		}
+		overtimes = append(overtimes, overtime)
+	}
+
+	return overtimes, rows.Err()
+}
+
+func (r *overtimeRepository) GetPendingByManager(ctx context.Context, managerID uuid.UUID) ([]models.Overtime, error) {
+	query := `
+		SELECT ` + prefixColumns("o", overtimeColumns) + `
+		FROM overtimes o
+		JOIN users u ON u.id = o.user_id
+		WHERE u.manager_id = $1 AND o.status = 'pending'
+		ORDER BY o.overtime_date, o.created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, managerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overtimes := []models.Overtime{}
+	for rows.Next() {
+		var overtime models.Overtime
+		if err := scanOvertime(rows, &overtime); err != nil {
+			return nil, err
+		}
+		overtimes = append(overtimes, overtime)
+	}
+
+	return overtimes, rows.Err()
+}
@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+const passwordResetTokenColumns = `
+	id, user_id, token_hash, expires_at, used_at, created_at, created_by
+`
+
+type passwordRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPasswordRepository(db *pgxpool.Pool) repository.PasswordRepository {
+	return &passwordRepository{db: db}
+}
+
+func scanPasswordResetToken(row pgx.Row, token *models.PasswordResetToken) error {
+	return row.Scan(
+		&token.ID, &token.UserID, &token.TokenHash, &token.ExpiresAt, &token.UsedAt,
+		&token.CreatedAt, &token.CreatedBy,
+	)
+}
+
+func (r *passwordRepository) RecentHashes(ctx context.Context, userID uuid.UUID, n int) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT password_hash
+		FROM password_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	`, userID, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hashes := []string{}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, rows.Err()
+}
+
+func (r *passwordRepository) CreateResetToken(ctx context.Context, token *models.PasswordResetToken) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE password_reset_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND used_at IS NULL
+	`, token.UserID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + passwordResetTokenColumns
+
+	err = scanPasswordResetToken(tx.QueryRow(ctx, query,
+		token.UserID, token.TokenHash, token.ExpiresAt, token.CreatedBy,
+	), token)
+	if err != nil {
+		return mapError(err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *passwordRepository) GetResetToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	query := `
+		SELECT ` + passwordResetTokenColumns + `
+		FROM password_reset_tokens
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+	`
+
+	if err := scanPasswordResetToken(r.db.QueryRow(ctx, query, tokenHash), &token); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &token, nil
+}
+
+func (r *passwordRepository) ChangePassword(ctx context.Context, userID uuid.UUID, passwordHash string, keepSessionID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, updatePasswordQuery, userID, passwordHash, false, userID)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	if err := revokeTokens(ctx, tx, `user_id = $1 AND session_id <> $2`, userID, keepSessionID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *passwordRepository) ResetPassword(ctx context.Context, tokenID, userID uuid.UUID, passwordHash, throttleSubject string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE password_reset_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND used_at IS NULL
+	`, tokenID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	tag, err = tx.Exec(ctx, updatePasswordQuery, userID, passwordHash, false, userID)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	if err := revokeTokens(ctx, tx, `user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM login_throttles WHERE scope = $1 AND subject = $2
+	`, models.LoginScopeUsername, throttleSubject)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
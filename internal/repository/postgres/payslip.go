@@ -0,0 +1,256 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/money"
+)
+
+// payslipSortColumns whitelists the columns a payroll summary can be sorted by.
+var payslipSortColumns = map[string]string{
+	"username": "u.username",
+	"amount":   "p.take_home_pay",
+}
+
+type payslipRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPayslipRepository(db *pgxpool.Pool) repository.PayslipRepository {
+	return &payslipRepository{db: db}
+}
+
+func (r *payslipRepository) ProcessPeriod(ctx context.Context, periodID, processedBy uuid.UUID, compute func(ctx context.Context) ([]models.Payslip, error)) ([]models.Payslip, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the period row up front so a concurrent run waits here and then
+	// sees payroll_processed = true once we commit. The period guard takes a
+	// share lock on the same row, so attendance, overtime and reimbursement
+	// writes already under way finish first and later ones wait, then fail
+	// once the period is processed.
+	var processed bool
+	err = tx.QueryRow(ctx, `
+		SELECT payroll_processed FROM attendance_periods WHERE id = $1 FOR UPDATE
+	`, periodID).Scan(&processed)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	if processed {
+		return nil, repository.ErrPeriodAlreadyProcessed
+	}
+
+	// Salaries and leave are not tied to a period, so their tables are held
+	// still while the payslips are computed. Reads carry on.
+	if _, err := tx.Exec(ctx, `LOCK TABLE salary_history, leave_requests IN SHARE MODE`); err != nil {
+		return nil, err
+	}
+
+	// compute reads through tx, so it sees the rows locked above and does
+	// not wait on a second pool connection while this one is held.
+	payslips, err := compute(withTx(ctx, tx))
+	if err != nil {
+		return nil, err
+	}
+
+	// Pay out the reimbursements included in the payslips before the period
+	// is flagged, as the period guard rejects writes to processed periods.
+	var reimbursementIDs []uuid.UUID
+	for i := range payslips {
+		reimbursementIDs = append(reimbursementIDs, payslips[i].ReimbursementIDs...)
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE reimbursements
+		SET status = 'paid', paid_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP, updated_by = $3
+		WHERE attendance_period_id = $1 AND status = 'approved' AND id = ANY($2)
+	`, periodID, reimbursementIDs, processedBy)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	query := `
+		INSERT INTO payslips (user_id, attendance_period_id, currency, base_salary, working_days, attendance_days, paid_leave_days,
+							  prorated_salary, overtime_hours, overtime_pay, reimbursement_total, take_home_pay, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at
+	`
+
+	rateQuery := `
+		INSERT INTO payslip_exchange_rates (payslip_id, from_currency, to_currency, rate, rate_date)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	for i := range payslips {
+		p := &payslips[i]
+		p.AttendancePeriodID = periodID
+		p.CreatedBy = processedBy
+
+		err := tx.QueryRow(ctx, query,
+			p.UserID, p.AttendancePeriodID, p.Currency, p.BaseSalary, p.WorkingDays, p.AttendanceDays, p.PaidLeaveDays,
+			p.ProratedSalary, p.OvertimeHours, p.OvertimePay, p.ReimbursementTotal, p.TakeHomePay, p.CreatedBy,
+		).Scan(&p.ID, &p.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rate := range p.ExchangeRates {
+			_, err := tx.Exec(ctx, rateQuery, p.ID, rate.FromCurrency, rate.ToCurrency, rate.Rate, rate.RateDate)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE attendance_periods
+		SET payroll_processed = true,
+			payroll_processed_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP,
+			updated_by = $2
+		WHERE id = $1
+	`, periodID, processedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return payslips, nil
+}
+
+func (r *payslipRepository) GetByUserAndPeriod(ctx context.Context, userID, periodID uuid.UUID) (*models.Payslip, error) {
+	var p models.Payslip
+	query := `
+		SELECT id, user_id, attendance_period_id, currency, base_salary, working_days, attendance_days, paid_leave_days,
+			   prorated_salary, overtime_hours, overtime_pay, reimbursement_total, take_home_pay,
+			   created_at, created_by
+		FROM payslips
+		WHERE user_id = $1 AND attendance_period_id = $2
+	`
+
+	err := r.db.QueryRow(ctx, query, userID, periodID).Scan(
+		&p.ID, &p.UserID, &p.AttendancePeriodID, &p.Currency, &p.BaseSalary, &p.WorkingDays, &p.AttendanceDays, &p.PaidLeaveDays,
+		&p.ProratedSalary, &p.OvertimeHours, &p.OvertimePay, &p.ReimbursementTotal, &p.TakeHomePay,
+		&p.CreatedAt, &p.CreatedBy,
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	for _, amount := range []*money.Money{&p.BaseSalary, &p.ProratedSalary, &p.OvertimePay, &p.ReimbursementTotal, &p.TakeHomePay} {
+		amount.Currency = p.Currency
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT from_currency, to_currency, rate, rate_date
+		FROM payslip_exchange_rates
+		WHERE payslip_id = $1
+		ORDER BY from_currency
+	`, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	p.ExchangeRates = []models.PayslipExchangeRate{}
+	for rows.Next() {
+		var rate models.PayslipExchangeRate
+		if err := rows.Scan(&rate.FromCurrency, &rate.ToCurrency, &rate.Rate, &rate.RateDate); err != nil {
+			return nil, err
+		}
+		p.ExchangeRates = append(p.ExchangeRates, rate)
+	}
+
+	return &p, rows.Err()
+}
+
+func (r *payslipRepository) ListSummariesByPeriod(ctx context.Context, periodID uuid.UUID, params models.PageParams) ([]models.PayslipSummary, error) {
+	column, ok := payslipSortColumns[params.SortBy]
+	if !ok {
+		column = payslipSortColumns["username"]
+	}
+	direction := "ASC"
+	if params.SortDesc {
+		direction = "DESC"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT p.user_id, u.username, p.currency, p.base_salary, p.prorated_salary, p.overtime_pay, p.reimbursement_total, p.take_home_pay
+		FROM payslips p
+		JOIN users u ON u.id = p.user_id
+		WHERE p.attendance_period_id = $1
+		ORDER BY %s %s, u.username ASC
+		LIMIT $2 OFFSET $3
+	`, column, direction)
+
+	rows, err := r.db.Query(ctx, query, periodID, params.PageSize, params.Offset())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := []models.PayslipSummary{}
+	for rows.Next() {
+		var s models.PayslipSummary
+		err := rows.Scan(
+			&s.UserID, &s.Username, &s.Currency, &s.BaseSalary, &s.ProratedSalary, &s.OvertimePay, &s.ReimbursementTotal, &s.TakeHomePay,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, amount := range []*money.Money{&s.BaseSalary, &s.ProratedSalary, &s.OvertimePay, &s.ReimbursementTotal, &s.TakeHomePay} {
+			amount.Currency = s.Currency
+		}
+		summaries = append(summaries, s)
+	}
+
+	return summaries, rows.Err()
+}
+
+func (r *payslipRepository) GetTotalsByPeriod(ctx context.Context, periodID uuid.UUID) ([]models.PayrollTotals, error) {
+	query := `
+		SELECT currency,
+			   COUNT(*),
+			   SUM(prorated_salary),
+			   SUM(overtime_pay),
+			   SUM(reimbursement_total),
+			   SUM(take_home_pay)
+		FROM payslips
+		WHERE attendance_period_id = $1
+		GROUP BY currency
+		ORDER BY currency
+	`
+
+	rows, err := r.db.Query(ctx, query, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := []models.PayrollTotals{}
+	for rows.Next() {
+		var t models.PayrollTotals
+		err := rows.Scan(
+			&t.Currency, &t.EmployeeCount, &t.ProratedSalary, &t.OvertimePay,
+			&t.ReimbursementTotal, &t.TakeHomePay,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, amount := range []*money.Money{&t.ProratedSalary, &t.OvertimePay, &t.ReimbursementTotal, &t.TakeHomePay} {
+			amount.Currency = t.Currency
+		}
+		totals = append(totals, t)
+	}
+
+	return totals, rows.Err()
+}
@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+const reimbursementColumns = `
+	id, user_id, attendance_period_id, amount, currency, description, receipt_url,
+	receipt_key, receipt_file_name, receipt_content_type, receipt_size, receipt_sha256,
+	status, reviewed_by, reviewed_at, review_reason, paid_at,
+	ip_address, created_at, updated_at, created_by, updated_by
+`
+
+// reimbursementSortColumns whitelists the columns a listing can be sorted by.
+var reimbursementSortColumns = map[string]string{
+	"created_at": "created_at",
+	"amount":     "amount",
+}
+
+type reimbursementRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReimbursementRepository(db *pgxpool.Pool) repository.ReimbursementRepository {
+	return &reimbursementRepository{db: db}
+}
+
+func scanReimbursement(row pgx.Row, reimbursement *models.Reimbursement) error {
+	err := row.Scan(
+		&reimbursement.ID, &reimbursement.UserID, &reimbursement.AttendancePeriodID,
+		&reimbursement.Amount, &reimbursement.Currency, &reimbursement.Description, &reimbursement.ReceiptURL,
+		&reimbursement.ReceiptKey, &reimbursement.ReceiptFileName, &reimbursement.ReceiptContentType,
+		&reimbursement.ReceiptSize, &reimbursement.ReceiptSHA256,
+		&reimbursement.Status, &reimbursement.ReviewedBy, &reimbursement.ReviewedAt,
+		&reimbursement.ReviewReason, &reimbursement.PaidAt, &reimbursement.IPAddress, &reimbursement.CreatedAt,
+		&reimbursement.UpdatedAt, &reimbursement.CreatedBy, &reimbursement.UpdatedBy,
+	)
+	reimbursement.Amount.Currency = reimbursement.Currency
+	return err
+}
+
+func (r *reimbursementRepository) Create(ctx context.Context, reimbursement *models.Reimbursement) error {
+	query := `
+		INSERT INTO reimbursements (user_id, attendance_period_id, amount, currency, description, receipt_url, status, ip_address, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + reimbursementColumns
+
+	err := scanReimbursement(r.db.QueryRow(ctx, query,
+		reimbursement.UserID, reimbursement.AttendancePeriodID, reimbursement.Amount, reimbursement.Currency,
+		reimbursement.Description, reimbursement.ReceiptURL, reimbursement.Status,
+		reimbursement.IPAddress, reimbursement.CreatedBy,
+	), reimbursement)
+	return mapError(err)
+}
+
+func (r *reimbursementRepository) Update(ctx context.Context, reimbursement *models.Reimbursement, fromStatus string) error {
+	query := `
+		UPDATE reimbursements
+		SET amount = $2, currency = $3, description = $4, receipt_url = $5,
+			receipt_key = $6, receipt_file_name = $7, receipt_content_type = $8,
+			receipt_size = $9, receipt_sha256 = $10, status = $11,
+			reviewed_by = $12, reviewed_at = $13, review_reason = $14,
+			updated_at = CURRENT_TIMESTAMP, updated_by = $15
+		WHERE id = $1 AND status = $16
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		reimbursement.ID, reimbursement.Amount, reimbursement.Currency, reimbursement.Description, reimbursement.ReceiptURL,
+		reimbursement.ReceiptKey, reimbursement.ReceiptFileName, reimbursement.ReceiptContentType,
+		reimbursement.ReceiptSize, reimbursement.ReceiptSHA256, reimbursement.Status,
+		reimbursement.ReviewedBy, reimbursement.ReviewedAt, reimbursement.ReviewReason, reimbursement.UpdatedBy,
+		fromStatus,
+	).Scan(&reimbursement.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.ErrStatusChanged
+	}
+	return mapError(err)
+}
+
+func (r *reimbursementRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Reimbursement, error) {
+	var reimbursement models.Reimbursement
+	query := `
+		SELECT ` + reimbursementColumns + `
+		FROM reimbursements
+		WHERE id = $1
+	`
+
+	if err := scanReimbursement(r.db.QueryRow(ctx, query, id), &reimbursement); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &reimbursement, nil
+}
+
+func (r *reimbursementRepository) GetActiveByReceiptHash(ctx context.Context, sha256 string) (*models.Reimbursement, error) {
+	var reimbursement models.Reimbursement
+	query := `
+		SELECT ` + reimbursementColumns + `
+		FROM reimbursements
+		WHERE receipt_sha256 = $1 AND status <> 'rejected'
+	`
+
+	if err := scanReimbursement(r.db.QueryRow(ctx, query, sha256), &reimbursement); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &reimbursement, nil
+}
+
+func (r *reimbursementRepository) List(ctx context.Context, filter models.ReimbursementFilter, params models.PageParams) ([]models.Reimbursement, int, error) {
+	var conditions []string
+	var args []any
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filter.PeriodID != nil {
+		args = append(args, *filter.PeriodID)
+		conditions = append(conditions, fmt.Sprintf("attendance_period_id = $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	column, ok := reimbursementSortColumns[params.SortBy]
+	if !ok {
+		column = reimbursementSortColumns["created_at"]
+	}
+	direction := "ASC"
+	if params.SortDesc {
+		direction = "DESC"
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM reimbursements " + where
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, params.PageSize, params.Offset())
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM reimbursements
+		%s
+		ORDER BY %s %s, id
+		LIMIT $%d OFFSET $%d
+	`, reimbursementColumns, where, column, direction, len(args)-1, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	reimbursements := []models.Reimbursement{}
+	for rows.Next() {
+		var reimbursement models.Reimbursement
+		if err := scanReimbursement(rows, &reimbursement); err != nil {
+			return nil, 0, err
+		}
+		reimbursements = append(reimbursements, reimbursement)
+	}
+
+	return reimbursements, total, rows.Err()
+}
+
+func (r *reimbursementRepository) GetByUserAndPeriod(ctx context.Context, userID, periodID uuid.UUID) ([]models.Reimbursement, error) {
+	query := `
+		SELECT ` + reimbursementColumns + `
+		FROM reimbursements
+		WHERE user_id = $1 AND attendance_period_id = $2
+		ORDER BY created_at
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID, periodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reimbursements := []models.Reimbursement{}
+	for rows.Next() {
+		var reimbursement models.Reimbursement
+		if err := scanReimbursement(rows, &reimbursement); err != nil {
+			return nil, err
+		}
+		reimbursements = append(reimbursements, reimbursement)
+	}
+
+	return reimbursements, rows.Err()
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+type roleRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRoleRepository(db *pgxpool.Pool) repository.RoleRepository {
+	return &roleRepository{db: db}
+}
+
+func (r *roleRepository) Exists(ctx context.Context, role string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM roles WHERE name = $1)`, role).Scan(&exists)
+	return exists, err
+}
+
+func (r *roleRepository) GetPermissions(ctx context.Context, role string) ([]string, error) {
+	query := `
+		SELECT permission
+		FROM role_permissions
+		WHERE role = $1
+		ORDER BY permission
+	`
+
+	rows, err := r.db.Query(ctx, query, role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []string{}
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+
+	return permissions, rows.Err()
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+const salaryChangeColumns = `
+	id, user_id, amount, currency, effective_from, reason, created_at, created_by
+`
+
+type salaryRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSalaryRepository(db *pgxpool.Pool) repository.SalaryRepository {
+	return &salaryRepository{db: db}
+}
+
+func scanSalaryChange(row pgx.Row, change *models.SalaryChange) error {
+	err := row.Scan(
+		&change.ID, &change.UserID, &change.Amount, &change.Currency, &change.EffectiveFrom,
+		&change.Reason, &change.CreatedAt, &change.CreatedBy,
+	)
+	change.Amount.Currency = change.Currency
+	return err
+}
+
+func (r *salaryRepository) Create(ctx context.Context, change *models.SalaryChange) error {
+	query := `
+		INSERT INTO salary_history (user_id, amount, currency, effective_from, reason, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + salaryChangeColumns
+
+	err := scanSalaryChange(r.db.QueryRow(ctx, query,
+		change.UserID, change.Amount, change.Currency, change.EffectiveFrom, change.Reason, change.CreatedBy,
+	), change)
+	return mapError(err)
+}
+
+func (r *salaryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM salary_history WHERE id = $1`, id)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *salaryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SalaryChange, error) {
+	var change models.SalaryChange
+	query := `
+		SELECT ` + salaryChangeColumns + `
+		FROM salary_history
+		WHERE id = $1
+	`
+
+	if err := scanSalaryChange(r.db.QueryRow(ctx, query, id), &change); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &change, nil
+}
+
+func (r *salaryRepository) GetByUser(ctx context.Context, userID uuid.UUID) ([]models.SalaryChange, error) {
+	query := `
+		SELECT ` + salaryChangeColumns + `
+		FROM salary_history
+		WHERE user_id = $1
+		ORDER BY effective_from
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []models.SalaryChange{}
+	for rows.Next() {
+		var change models.SalaryChange
+		if err := scanSalaryChange(rows, &change); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
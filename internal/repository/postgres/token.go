@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+const refreshTokenColumns = `
+	id, user_id, session_id, token_hash, access_jti, access_expires_at,
+	expires_at, created_at, used_at, revoked_at
+`
+
+type tokenRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTokenRepository(db *pgxpool.Pool) repository.TokenRepository {
+	return &tokenRepository{db: db}
+}
+
+func scanRefreshToken(row pgx.Row, token *models.RefreshToken) error {
+	return row.Scan(
+		&token.ID, &token.UserID, &token.SessionID, &token.TokenHash, &token.AccessJTI, &token.AccessExpiresAt,
+		&token.ExpiresAt, &token.CreatedAt, &token.UsedAt, &token.RevokedAt,
+	)
+}
+
+func (r *tokenRepository) CreateRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (user_id, session_id, token_hash, access_jti, access_expires_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + refreshTokenColumns
+
+	err := scanRefreshToken(r.db.QueryRow(ctx, query,
+		token.UserID, token.SessionID, token.TokenHash, token.AccessJTI, token.AccessExpiresAt, token.ExpiresAt,
+	), token)
+	return mapError(err)
+}
+
+func (r *tokenRepository) UseRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	query := `
+		UPDATE refresh_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1
+		  AND used_at IS NULL
+		  AND revoked_at IS NULL
+		  AND expires_at > CURRENT_TIMESTAMP
+		RETURNING ` + refreshTokenColumns
+
+	if err := scanRefreshToken(r.db.QueryRow(ctx, query, tokenHash), &token); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &token, nil
+}
+
+func (r *tokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	query := `
+		SELECT ` + refreshTokenColumns + `
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+
+	if err := scanRefreshToken(r.db.QueryRow(ctx, query, tokenHash), &token); err != nil {
+		return nil, mapError(err)
+	}
+
+	return &token, nil
+}
+
+func (r *tokenRepository) GetSessionIDByAccessJTI(ctx context.Context, jti uuid.UUID) (uuid.UUID, error) {
+	var sessionID uuid.UUID
+	err := r.db.QueryRow(ctx, `
+		SELECT session_id FROM refresh_tokens WHERE access_jti = $1
+	`, jti).Scan(&sessionID)
+	if err != nil {
+		return uuid.Nil, mapError(err)
+	}
+	return sessionID, nil
+}
+
+func (r *tokenRepository) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	return r.revoke(ctx, `session_id = $1`, sessionID)
+}
+
+func (r *tokenRepository) RevokeUser(ctx context.Context, userID uuid.UUID) error {
+	return r.revoke(ctx, `user_id = $1`, userID)
+}
+
+// revoke revokes the refresh tokens matching where, and the access tokens
+// issued with them that have not expired yet, in one transaction.
+func (r *tokenRepository) revoke(ctx context.Context, where string, args ...any) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := revokeTokens(ctx, tx, where, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// revokeTokens does the work of revoke inside tx, for callers that revoke
+// sessions as part of a larger change.
+func revokeTokens(ctx context.Context, tx pgx.Tx, where string, args ...any) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		SELECT access_jti, user_id, access_expires_at
+		FROM refresh_tokens
+		WHERE `+where+` AND access_expires_at > CURRENT_TIMESTAMP
+		ON CONFLICT (jti) DO NOTHING
+	`, args...)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE `+where+` AND revoked_at IS NULL
+	`, args...)
+	return err
+}
+
+func (r *tokenRepository) IsAccessTokenRevoked(ctx context.Context, jti uuid.UUID) (bool, error) {
+	var revoked bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+	`, jti).Scan(&revoked)
+	return revoked, err
+}
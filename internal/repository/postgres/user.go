@@ -2,13 +2,56 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jordanhimawan/payroll-mgmt/internal/models"
 	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
 )
 
+// userColumns selects the salary in force today from the salary history.
+const userColumns = `
+	id, username, password_hash, role,
+	(
+		SELECT s.amount FROM salary_history s
+		WHERE s.user_id = users.id AND s.effective_from <= CURRENT_DATE
+		ORDER BY s.effective_from DESC
+		LIMIT 1
+	) AS salary,
+	COALESCE((
+		SELECT s.currency FROM salary_history s
+		WHERE s.user_id = users.id AND s.effective_from <= CURRENT_DATE
+		ORDER BY s.effective_from DESC
+		LIMIT 1
+	), '') AS salary_currency,
+	manager_id, location_id, is_active, mfa_enabled, mfa_secret, must_change_password, created_at, updated_at, created_by, updated_by
+`
+
+// userSortColumns whitelists the columns a user listing can be sorted by.
+var userSortColumns = map[string]string{
+	"username":   "username",
+	"created_at": "created_at",
+	"salary":     "salary",
+}
+
+// updatePasswordQuery sets a user's password and adds it to the password
+// history. It takes the user ID, hash, must-change flag and updater.
+const updatePasswordQuery = `
+	WITH updated AS (
+		UPDATE users
+		SET password_hash = $2, must_change_password = $3, password_changed_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP, updated_by = $4
+		WHERE id = $1
+		RETURNING id, password_hash
+	)
+	INSERT INTO password_history (user_id, password_hash)
+	SELECT id, password_hash FROM updated
+`
+
 type userRepository struct {
 	db *pgxpool.Pool
 }
@@ -17,22 +60,29 @@ func NewUserRepository(db *pgxpool.Pool) repository.UserRepository {
 	return &userRepository{db: db}
 }
 
+func scanUser(row pgx.Row, user *models.User) error {
+	err := row.Scan(
+		&user.ID, &user.Username, &user.PasswordHash, &user.Role,
+		&user.Salary, &user.SalaryCurrency, &user.ManagerID, &user.LocationID, &user.IsActive,
+		&user.MFAEnabled, &user.MFASecret, &user.MustChangePassword,
+		&user.CreatedAt, &user.UpdatedAt, &user.CreatedBy, &user.UpdatedBy,
+	)
+	if err == nil && user.Salary != nil {
+		user.Salary.Currency = user.SalaryCurrency
+	}
+	return err
+}
+
 func (r *userRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	var user models.User
 	query := `
-		SELECT id, username, password_hash, role, salary, is_active, created_at, updated_at, created_by, updated_by
+		SELECT ` + userColumns + `
 		FROM users 
 		WHERE username = $1 AND is_active = true
 	`
 
-	err := r.db.QueryRow(ctx, query, username).Scan(
-		&user.ID, &user.Username, &user.PasswordHash, &user.Role,
-		&user.Salary, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
-		&user.CreatedBy, &user.UpdatedBy,
-	)
-
-	if err != nil {
-		return nil, err
+	if err := scanUser(r.db.QueryRow(ctx, query, username), &user); err != nil {
+		return nil, mapError(err)
 	}
 
 	return &user, nil
@@ -41,32 +91,224 @@ func (r *userRepository) GetByUsername(ctx context.Context, username string) (*m
 func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	var user models.User
 	query := `
-		SELECT id, username, password_hash, role, salary, is_active, created_at, updated_at, created_by, updated_by
+		SELECT ` + userColumns + `
 		FROM users 
 		WHERE id = $1 AND is_active = true
 	`
 
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&user.ID, &user.Username, &user.PasswordHash, &user.Role,
-		&user.Salary, &user.IsActive, &user.CreatedAt, &user.UpdatedAt,
-		&user.CreatedBy, &user.UpdatedBy,
-	)
+	if err := scanUser(r.db.QueryRow(ctx, query, id), &user); err != nil {
+		return nil, mapError(err)
+	}
 
-	if err != nil {
-		return nil, err
+	return &user, nil
+}
+
+func (r *userRepository) GetByIDIncludingInactive(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user models.User
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = $1
+	`
+
+	if err := scanUser(r.db.QueryRow(ctx, query, id), &user); err != nil {
+		return nil, mapError(err)
 	}
 
 	return &user, nil
 }
 
-func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+func (r *userRepository) Create(ctx context.Context, user *models.User, salaryFrom time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-		INSERT INTO users (id, username, password_hash, role, salary, created_by)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO users (id, username, password_hash, must_change_password, role, manager_id, location_id, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING created_at, updated_at
 	`
 
-	return r.db.QueryRow(ctx, query,
-		user.ID, user.Username, user.PasswordHash, user.Role, user.Salary, user.CreatedBy,
+	err = tx.QueryRow(ctx, query,
+		user.ID, user.Username, user.PasswordHash, user.MustChangePassword, user.Role, user.ManagerID, user.LocationID, user.CreatedBy,
 	).Scan(&user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return mapError(err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO password_history (user_id, password_hash) VALUES ($1, $2)
+	`, user.ID, user.PasswordHash)
+	if err != nil {
+		return err
+	}
+
+	if user.Salary != nil {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO salary_history (user_id, amount, currency, effective_from, reason, created_by)
+			VALUES ($1, $2, $3, $4, 'Initial salary', $5)
+		`, user.ID, *user.Salary, user.SalaryCurrency, salaryFrom, user.CreatedBy)
+		if err != nil {
+			return mapError(err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *userRepository) GetPayableInPeriod(ctx context.Context, periodID uuid.UUID, start, end time.Time) ([]models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE is_active = true
+		   OR EXISTS (
+				SELECT 1 FROM attendances a
+				WHERE a.user_id = users.id AND a.attendance_period_id = $1 AND a.is_present
+		   )
+		   OR EXISTS (
+				SELECT 1 FROM overtimes o
+				WHERE o.user_id = users.id AND o.attendance_period_id = $1 AND o.status = 'approved'
+		   )
+		   OR EXISTS (
+				SELECT 1 FROM reimbursements rb
+				WHERE rb.user_id = users.id AND rb.attendance_period_id = $1 AND rb.status = 'approved'
+		   )
+		   OR EXISTS (
+				SELECT 1 FROM leave_requests l
+				JOIN leave_types t ON t.code = l.leave_type
+				WHERE l.user_id = users.id AND l.status = 'approved' AND t.is_paid
+				  AND l.start_date <= $3 AND l.end_date >= $2
+		   )
+		ORDER BY username
+	`
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, periodID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		if err := scanUser(rows, &user); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *userRepository) List(ctx context.Context, filter models.UserFilter, params models.PageParams) ([]models.User, int, error) {
+	var conditions []string
+	var args []any
+
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		conditions = append(conditions, fmt.Sprintf("role = $%d", len(args)))
+	}
+	if filter.IsActive != nil {
+		args = append(args, *filter.IsActive)
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", len(args)))
+	}
+	if filter.ManagerID != nil {
+		args = append(args, *filter.ManagerID)
+		conditions = append(conditions, fmt.Sprintf("manager_id = $%d", len(args)))
+	}
+	if filter.LocationID != nil {
+		args = append(args, *filter.LocationID)
+		conditions = append(conditions, fmt.Sprintf("location_id = $%d", len(args)))
+	}
+	if filter.Search != "" {
+		args = append(args, "%"+escapeLike(filter.Search)+"%")
+		conditions = append(conditions, fmt.Sprintf("username ILIKE $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	column, ok := userSortColumns[params.SortBy]
+	if !ok {
+		column = userSortColumns["username"]
+	}
+	direction := "ASC"
+	if params.SortDesc {
+		direction = "DESC"
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM users " + where
+	if err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, params.PageSize, params.Offset())
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM users
+		%s
+		ORDER BY %s %s NULLS LAST, id
+		LIMIT $%d OFFSET $%d
+	`, userColumns, where, column, direction, len(args)-1, len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := scanUser(rows, &user); err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+
+	return users, total, rows.Err()
+}
+
+func (r *userRepository) Update(ctx context.Context, user *models.User) error {
+	query := `
+		UPDATE users
+		SET username = $2, role = $3, manager_id = $4, location_id = $5,
+			is_active = $6, updated_at = CURRENT_TIMESTAMP, updated_by = $7
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		user.ID, user.Username, user.Role, user.ManagerID, user.LocationID,
+		user.IsActive, user.UpdatedBy,
+	).Scan(&user.UpdatedAt)
+	return mapError(err)
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string, mustChange bool, updatedBy uuid.UUID) error {
+	tag, err := r.db.Exec(ctx, updatePasswordQuery, id, passwordHash, mustChange, updatedBy)
+	if err != nil {
+		return mapError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) RehashPassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE users SET password_hash = $2 WHERE id = $1
+	`, id, passwordHash)
+	return mapError(err)
+}
+
+// escapeLike escapes the LIKE wildcards in a user-supplied search term.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+// maxShiftLength is the longest time a check-out may follow its check-in.
+const maxShiftLength = 24 * time.Hour
+
+type AttendanceService struct {
+	attendanceRepo  repository.AttendanceRepository
+	periodRepo      repository.AttendancePeriodRepository
+	userRepo        repository.UserRepository
+	calendarService *CalendarService
+}
+
+func NewAttendanceService(
+	attendanceRepo repository.AttendanceRepository,
+	periodRepo repository.AttendancePeriodRepository,
+	userRepo repository.UserRepository,
+	calendarService *CalendarService,
+) *AttendanceService {
+	return &AttendanceService{
+		attendanceRepo:  attendanceRepo,
+		periodRepo:      periodRepo,
+		userRepo:        userRepo,
+		calendarService: calendarService,
+	}
+}
+
+// CheckIn records the user's arrival for today, which has to be a working day
+// at the user's location.
+func (s *AttendanceService) CheckIn(ctx context.Context, userID uuid.UUID, req models.CheckInRequest, ipAddress string) (*models.Attendance, error) {
+	attendanceDate := s.calendarService.Today()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar, err := s.calendarService.ForUser(ctx, user, attendanceDate, attendanceDate)
+	if err != nil {
+		return nil, err
+	}
+	if calendar.IsWeekend(attendanceDate) {
+		return nil, ErrWeekendAttendance
+	}
+	if _, ok := calendar.Holiday(attendanceDate); ok {
+		return nil, ErrHolidayAttendance
+	}
+
+	period, err := resolvePeriod(ctx, s.periodRepo, req.AttendancePeriodID, attendanceDate, attendanceDate)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.attendanceRepo.GetByUserAndDate(ctx, userID, attendanceDate)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrAlreadyCheckedIn
+	}
+
+	now := time.Now()
+	attendance := &models.Attendance{
+		UserID:             userID,
+		AttendancePeriodID: period.ID,
+		AttendanceDate:     attendanceDate,
+		CheckInTime:        &now,
+		IPAddress:          ipAddress,
+		CreatedBy:          userID,
+	}
+
+	if err := s.attendanceRepo.Create(ctx, attendance); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrAlreadyCheckedIn
+		}
+		return nil, mapWriteError(err)
+	}
+
+	return attendance, nil
+}
+
+// CheckOut closes the user's latest attendance and stores the minutes worked.
+// The attendance is not looked up by today's date, so shifts that run past
+// midnight can still be closed.
+func (s *AttendanceService) CheckOut(ctx context.Context, userID uuid.UUID) (*models.Attendance, error) {
+	attendance, err := s.attendanceRepo.GetLatestByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotCheckedIn
+		}
+		return nil, err
+	}
+
+	if attendance.CheckInTime == nil {
+		return nil, ErrNotCheckedIn
+	}
+	if attendance.CheckOutTime != nil {
+		return nil, ErrAlreadyCheckedOut
+	}
+
+	if _, err := openPeriod(ctx, s.periodRepo, attendance.AttendancePeriodID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if now.Before(*attendance.CheckInTime) {
+		return nil, ErrCheckOutBeforeCheckIn
+	}
+	// A check-in left open for days is a forgotten check-out, not a shift,
+	// and must not be paid as one.
+	if now.Sub(*attendance.CheckInTime) > maxShiftLength {
+		return nil, ErrShiftTooLong
+	}
+
+	workedMinutes := int(now.Sub(*attendance.CheckInTime) / time.Minute)
+	attendance.CheckOutTime = &now
+	attendance.WorkedMinutes = &workedMinutes
+	attendance.UpdatedBy = &userID
+
+	if err := s.attendanceRepo.Update(ctx, attendance); err != nil {
+		return nil, mapWriteError(err)
+	}
+
+	return attendance, nil
+}
+
+// Errors
+var (
+	ErrInvalidPeriodID       = NewAppError("invalid attendance period ID", 400)
+	ErrWeekendAttendance     = NewAppError("cannot submit attendance on weekends", 400)
+	ErrHolidayAttendance     = NewAppError("cannot submit attendance on a public holiday or company closure", 400)
+	ErrAlreadyCheckedIn      = NewAppError("already checked in today", 409)
+	ErrNotCheckedIn          = NewAppError("you have not checked in", 409)
+	ErrAlreadyCheckedOut     = NewAppError("already checked out", 409)
+	ErrCheckOutBeforeCheckIn = NewAppError("check-out must be after check-in", 409)
+	ErrShiftTooLong          = NewAppError("check-out must be within 24 hours of check-in", 409)
+)
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+type AuditService struct {
+	auditRepo repository.AuditRepository
+}
+
+func NewAuditService(auditRepo repository.AuditRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// ListAuditLogs lists audit log entries matching filter, newest first.
+func (s *AuditService) ListAuditLogs(ctx context.Context, filter models.AuditLogFilter, params models.PageParams) (*models.AuditLogListResponse, error) {
+	// Entity IDs are only unique within a table.
+	if filter.EntityID != "" && filter.EntityType == "" {
+		return nil, ErrAuditEntityTypeRequired
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, ErrInvalidAuditTimeRange
+	}
+
+	entries, total, err := s.auditRepo.List(ctx, filter, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuditLogListResponse{
+		Entries:    entries,
+		Pagination: models.NewPagination(params, total),
+	}, nil
+}
+
+// Errors
+var (
+	ErrAuditEntityTypeRequired = NewAppError("entity_type is required when filtering by entity_id", 400)
+	ErrInvalidAuditTimeRange   = NewAppError("from must be before to", 400)
+)
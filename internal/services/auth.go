@@ -2,59 +2,359 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/jwtkeys"
 	"github.com/jordanhimawan/payroll-mgmt/internal/models"
 	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/utils"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Login throttling. Once a username or client IP reaches its free attempts,
+// each further failure locks it for twice as long as the last, up to
+// maxLockout. Counts restart after failureWindow without failures.
+const (
+	usernameFreeAttempts = 5
+	ipFreeAttempts       = 20
+	baseLockout          = time.Minute
+	maxLockout           = time.Hour
+	failureWindow        = 24 * time.Hour
+)
+
 type AuthService struct {
-	userRepo  repository.UserRepository
-	jwtSecret []byte
+	userRepo       repository.UserRepository
+	roleRepo       repository.RoleRepository
+	tokenRepo      repository.TokenRepository
+	loginRepo      repository.LoginRepository
+	keys           *jwtkeys.KeySet
+	accessTTL      time.Duration
+	refreshTTL     time.Duration
+	mfaRepo        repository.MFARepository
+	mfaIssuer      string
+	passwordRepo   repository.PasswordRepository
+	passwordPolicy *PasswordPolicy
+	// dummyHash is checked against when the username is unknown, so the
+	// response takes as long as for a wrong password.
+	dummyHash []byte
 }
 
+// Claims carries the permissions resolved for the user's role at login or
+// refresh, so role changes take effect once the access token is renewed. ID
+// (the jti) identifies the token on the revocation list.
 type Claims struct {
-	UserID   uuid.UUID `json:"user_id"`
-	Username string    `json:"username"`
-	Role     string    `json:"role"`
+	UserID      uuid.UUID `json:"user_id"`
+	Username    string    `json:"username"`
+	Role        string    `json:"role"`
+	Permissions []string  `json:"permissions"`
 	jwt.RegisteredClaims
 }
 
-func NewAuthService(userRepo repository.UserRepository, jwtSecret string) *AuthService {
-	return &AuthService{
-		userRepo:  userRepo,
-		jwtSecret: []byte(jwtSecret),
+func (c *Claims) HasPermission(permission string) bool {
+	for _, p := range c.Permissions {
+		if p == permission {
+			return true
+		}
 	}
+	return false
 }
 
-func (s *AuthService) Login(ctx context.Context, username, password string) (*models.LoginResponse, error) {
-	user, err := s.userRepo.GetByUsername(ctx, username)
+func NewAuthService(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	tokenRepo repository.TokenRepository,
+	loginRepo repository.LoginRepository,
+	mfaRepo repository.MFARepository,
+	passwordRepo repository.PasswordRepository,
+	passwordPolicy *PasswordPolicy,
+	keys *jwtkeys.KeySet,
+	accessTTL, refreshTTL time.Duration,
+	mfaIssuer string,
+) (*AuthService, error) {
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte(uuid.NewString()), utils.PasswordHashCost)
 	if err != nil {
 		return nil, err
 	}
 
-	if !s.checkPassword(password, user.PasswordHash) {
+	return &AuthService{
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		tokenRepo:      tokenRepo,
+		loginRepo:      loginRepo,
+		keys:           keys,
+		accessTTL:      accessTTL,
+		refreshTTL:     refreshTTL,
+		mfaRepo:        mfaRepo,
+		mfaIssuer:      mfaIssuer,
+		passwordRepo:   passwordRepo,
+		passwordPolicy: passwordPolicy,
+		dummyHash:      dummyHash,
+	}, nil
+}
+
+// Login checks the credentials and starts a new session. Every attempt is
+// recorded in the login history. Failures count against both the username
+// and the client IP, and either can be locked out; unknown usernames are
+// throttled and answered exactly like wrong passwords.
+//
+// Users with MFA, and users whose role requires it, get an MFA token instead
+// of a session; see VerifyMFA and ActivateMFA.
+func (s *AuthService) Login(ctx context.Context, username, password, clientIP, userAgent string) (*models.LoginResponse, error) {
+	attempt := &models.LoginHistory{Username: username, IPAddress: clientIP, UserAgent: userAgent}
+	subjects := loginSubjects(username, clientIP)
+
+	if err := s.checkLocked(ctx, subjects, attempt); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByUsername(ctx, username)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	hash := s.dummyHash
+	if user != nil {
+		attempt.UserID = &user.ID
+		hash = []byte(user.PasswordHash)
+	}
+
+	if !s.checkPassword(password, hash) || user == nil {
+		if err := s.recordFailure(ctx, subjects, attempt, models.LoginFailureInvalidCredentials); err != nil {
+			return nil, err
+		}
 		return nil, ErrInvalidCredentials
 	}
 
-	token, err := s.generateToken(user)
+	if err := s.rehashIfNeeded(ctx, user, password); err != nil {
+		return nil, err
+	}
+
+	// The attempt is only recorded once the second factor is in, and the
+	// username's failures keep counting until then.
+	if user.MFAEnabled {
+		return s.startMFA(ctx, user, models.MFAPurposeVerify)
+	}
+	required, err := s.requiresMFA(ctx, user)
 	if err != nil {
 		return nil, err
 	}
+	if required {
+		return s.startMFA(ctx, user, models.MFAPurposeEnroll)
+	}
 
-	return &models.LoginResponse{
-		Token: token,
-		User:  *user,
-	}, nil
+	return s.completeLogin(ctx, user, attempt)
 }
 
-func (s *AuthService) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return s.jwtSecret, nil
-	})
+// completeLogin records a successful login and starts a session, unless the
+// password must be changed first; then it hands out a reset token instead.
+func (s *AuthService) completeLogin(ctx context.Context, user *models.User, attempt *models.LoginHistory) (*models.LoginResponse, error) {
+	// The IP count is left alone: one valid account must not let an address
+	// keep guessing at others.
+	if err := s.loginRepo.Reset(ctx, models.LoginScopeUsername, strings.ToLower(user.Username)); err != nil {
+		return nil, err
+	}
+	if err := s.recordAttempt(ctx, attempt, ""); err != nil {
+		return nil, err
+	}
+
+	if user.MustChangePassword {
+		reset, err := issueResetToken(ctx, s.passwordRepo, user.ID, nil, passwordChangeTTL)
+		if err != nil {
+			return nil, err
+		}
+		return &models.LoginResponse{PasswordChangeRequired: true, PasswordResetToken: reset.ResetToken}, nil
+	}
+
+	return s.issueTokens(ctx, user, uuid.New())
+}
+
+// loginSubjects returns the throttle subjects a login attempt counts against.
+func loginSubjects(username, clientIP string) [][2]string {
+	return [][2]string{
+		{models.LoginScopeUsername, strings.ToLower(username)},
+		{models.LoginScopeIP, clientIP},
+	}
+}
+
+// checkLocked fails with a 429 naming when to retry if any subject is
+// locked, recording the refused attempt.
+func (s *AuthService) checkLocked(ctx context.Context, subjects [][2]string, attempt *models.LoginHistory) error {
+	lockedUntil, err := s.lockedUntil(ctx, subjects)
+	if err != nil {
+		return err
+	}
+	if lockedUntil.IsZero() {
+		return nil
+	}
+
+	if err := s.recordAttempt(ctx, attempt, models.LoginFailureLocked); err != nil {
+		return err
+	}
+	return NewAppError(fmt.Sprintf("too many failed login attempts; try again after %s", lockedUntil.UTC().Format(time.RFC3339)), 429)
+}
+
+// lockedUntil returns the latest lock end among subjects, or the zero time
+// if none is locked.
+func (s *AuthService) lockedUntil(ctx context.Context, subjects [][2]string) (time.Time, error) {
+	var latest time.Time
+	for _, subject := range subjects {
+		until, err := s.loginRepo.LockedUntil(ctx, subject[0], subject[1])
+		if err != nil {
+			return time.Time{}, err
+		}
+		if until.After(latest) {
+			latest = until
+		}
+	}
+	return latest, nil
+}
+
+// recordFailure counts a failed attempt against each subject, locks those
+// past their free attempts and records the attempt.
+func (s *AuthService) recordFailure(ctx context.Context, subjects [][2]string, attempt *models.LoginHistory, reason string) error {
+	now := time.Now()
+	for _, subject := range subjects {
+		count, err := s.loginRepo.RecordFailure(ctx, subject[0], subject[1], now.Add(-failureWindow))
+		if err != nil {
+			return err
+		}
+
+		free := usernameFreeAttempts
+		if subject[0] == models.LoginScopeIP {
+			free = ipFreeAttempts
+		}
+		if count < free {
+			continue
+		}
+
+		if err := s.loginRepo.Lock(ctx, subject[0], subject[1], now.Add(lockoutFor(count-free))); err != nil {
+			return err
+		}
+	}
+	return s.recordAttempt(ctx, attempt, reason)
+}
+
+// lockoutFor returns how long to lock for after n failures beyond the free
+// attempts.
+func lockoutFor(n int) time.Duration {
+	lockout := baseLockout
+	for i := 0; i < n && lockout < maxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > maxLockout {
+		lockout = maxLockout
+	}
+	return lockout
+}
+
+// recordAttempt adds attempt to the login history; an empty failureReason
+// marks it successful.
+func (s *AuthService) recordAttempt(ctx context.Context, attempt *models.LoginHistory, failureReason string) error {
+	attempt.Success = failureReason == ""
+	if !attempt.Success {
+		attempt.FailureReason = &failureReason
+	}
+	return s.loginRepo.CreateHistory(ctx, attempt)
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token in the
+// same session. Each refresh token works once: presenting a used one again
+// means it was copied, so the whole session is revoked.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*models.LoginResponse, error) {
+	hash := hashToken(refreshToken)
+
+	used, err := s.tokenRepo.UseRefreshToken(ctx, hash)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		if err := s.revokeReusedToken(ctx, hash); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Deactivated users are not found, and their sessions were revoked with
+	// them; this covers a refresh racing the deactivation.
+	user, err := s.userRepo.GetByID(ctx, used.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	// A role that came to require MFA after the session started needs a
+	// fresh login to enrol.
+	if !user.MFAEnabled {
+		required, err := s.requiresMFA(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		if required {
+			if err := s.tokenRepo.RevokeSession(ctx, used.SessionID); err != nil {
+				return nil, err
+			}
+			return nil, ErrMFAEnrollmentRequired
+		}
+	}
+
+	return s.issueTokens(ctx, user, used.SessionID)
+}
+
+// revokeReusedToken revokes the session of a refresh token that was already
+// used. Unknown, expired and revoked tokens are left alone.
+func (s *AuthService) revokeReusedToken(ctx context.Context, hash string) error {
+	token, err := s.tokenRepo.GetRefreshTokenByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if token.UsedAt == nil || token.RevokedAt != nil {
+		return nil
+	}
+	return s.tokenRepo.RevokeSession(ctx, token.SessionID)
+}
+
+// Logout revokes the session the access token belongs to, or every session of
+// its user when allSessions is set.
+func (s *AuthService) Logout(ctx context.Context, claims *Claims, allSessions bool) error {
+	if allSessions {
+		return s.tokenRepo.RevokeUser(ctx, claims.UserID)
+	}
+
+	jti, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return ErrInvalidToken
+	}
+
+	sessionID, err := s.tokenRepo.GetSessionIDByAccessJTI(ctx, jti)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+
+	return s.tokenRepo.RevokeSession(ctx, sessionID)
+}
+
+// ValidateToken checks the token's signature against the key named by its kid,
+// its expiry, and that it has not been revoked.
+func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, s.keys.Keyfunc, jwt.WithValidMethods(s.keys.Methods()))
 
 	if err != nil || !token.Valid {
 		return nil, ErrInvalidToken
@@ -65,33 +365,109 @@ func (s *AuthService) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, ErrInvalidToken
 	}
 
+	jti, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	revoked, err := s.tokenRepo.IsAccessTokenRevoked(ctx, jti)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidToken
+	}
+
 	return claims, nil
 }
 
-func (s *AuthService) generateToken(user *models.User) (string, error) {
+// issueTokens signs an access token for user and stores the refresh token
+// that renews it within sessionID.
+func (s *AuthService) issueTokens(ctx context.Context, user *models.User, sessionID uuid.UUID) (*models.LoginResponse, error) {
+	permissions, err := s.roleRepo.GetPermissions(ctx, user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	jti := uuid.New()
+	expiresAt := now.Add(s.accessTTL)
+
+	token, err := s.generateToken(user, permissions, jti, now, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.tokenRepo.CreateRefreshToken(ctx, &models.RefreshToken{
+		UserID:          user.ID,
+		SessionID:       sessionID,
+		TokenHash:       hashToken(refreshToken),
+		AccessJTI:       jti,
+		AccessExpiresAt: expiresAt,
+		ExpiresAt:       now.Add(s.refreshTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		Token:        token,
+		ExpiresAt:    &expiresAt,
+		RefreshToken: refreshToken,
+		User:         user,
+	}, nil
+}
+
+func (s *AuthService) generateToken(user *models.User, permissions []string, jti uuid.UUID, issuedAt, expiresAt time.Time) (string, error) {
 	claims := &Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     user.Role,
+		UserID:      user.ID,
+		Username:    user.Username,
+		Role:        user.Role,
+		Permissions: permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        jti.String(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.jwtSecret)
+	return s.keys.Sign(claims)
+}
+
+// newToken returns 256 random bits, base64url encoded.
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// JWKS returns the public keys access tokens can be verified with.
+func (s *AuthService) JWKS() jwtkeys.JWKS {
+	return s.keys.JWKS()
 }
 
-func (s *AuthService) checkPassword(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+func (s *AuthService) checkPassword(password string, hash []byte) bool {
+	err := bcrypt.CompareHashAndPassword(hash, []byte(password))
 	return err == nil
 }
 
 // Errors
 var (
-	ErrInvalidCredentials = NewAppError("invalid credentials", 401)
-	ErrInvalidToken       = NewAppError("invalid token", 401)
+	ErrInvalidCredentials  = NewAppError("invalid credentials", 401)
+	ErrInvalidToken        = NewAppError("invalid token", 401)
+	ErrInvalidRefreshToken = NewAppError("invalid or expired refresh token", 401)
 )
 
 type AppError struct {
@@ -0,0 +1,448 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/ical"
+)
+
+// maxImportedHolidays bounds a single .ics import to roughly a year of days.
+const maxImportedHolidays = 366
+
+// Calendar knows which days a location works within the range it was loaded
+// for.
+type Calendar struct {
+	weekend  map[time.Weekday]bool
+	holidays map[string]models.Holiday
+}
+
+// Holiday returns the holiday or closure on date, if there is one.
+func (c *Calendar) Holiday(date time.Time) (*models.Holiday, bool) {
+	holiday, ok := c.holidays[date.Format(dateLayout)]
+	if !ok {
+		return nil, false
+	}
+	return &holiday, true
+}
+
+func (c *Calendar) IsWeekend(date time.Time) bool {
+	return c.weekend[date.Weekday()]
+}
+
+func (c *Calendar) IsWorkingDay(date time.Time) bool {
+	if c.IsWeekend(date) {
+		return false
+	}
+	_, holiday := c.Holiday(date)
+	return !holiday
+}
+
+// WorkingDays counts the working days between start and end inclusive.
+func (c *Calendar) WorkingDays(start, end time.Time) int {
+	days := 0
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		if c.IsWorkingDay(date) {
+			days++
+		}
+	}
+	return days
+}
+
+type CalendarService struct {
+	calendarRepo repository.CalendarRepository
+	periodRepo   repository.AttendancePeriodRepository
+	timeZone     *time.Location
+}
+
+func NewCalendarService(calendarRepo repository.CalendarRepository, periodRepo repository.AttendancePeriodRepository, timeZone *time.Location) *CalendarService {
+	return &CalendarService{
+		calendarRepo: calendarRepo,
+		periodRepo:   periodRepo,
+		timeZone:     timeZone,
+	}
+}
+
+// Today returns the current date in the configured time zone. Like DATE
+// columns and request dates, it is represented as midnight UTC.
+func (s *CalendarService) Today() time.Time {
+	year, month, day := time.Now().In(s.timeZone).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// ForUser loads the calendar of the user's location between start and end.
+func (s *CalendarService) ForUser(ctx context.Context, user *models.User, start, end time.Time) (*Calendar, error) {
+	return s.ForLocation(ctx, user.LocationID, start, end)
+}
+
+// ForLocation loads the calendar of a location between start and end. A nil
+// location means the default one.
+func (s *CalendarService) ForLocation(ctx context.Context, locationID *uuid.UUID, start, end time.Time) (*Calendar, error) {
+	var location *models.Location
+	var err error
+	if locationID == nil {
+		location, err = s.calendarRepo.GetDefaultLocation(ctx)
+	} else {
+		location, err = s.calendarRepo.GetLocation(ctx, *locationID)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrLocationNotFound
+		}
+		return nil, err
+	}
+
+	holidays, err := s.calendarRepo.ListHolidays(ctx, models.HolidayFilter{
+		From:       start,
+		To:         end,
+		LocationID: &location.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &Calendar{
+		weekend:  make(map[time.Weekday]bool, len(location.WeekendDays)),
+		holidays: make(map[string]models.Holiday, len(holidays)),
+	}
+	for _, day := range location.WeekendDays {
+		calendar.weekend[time.Weekday(day)] = true
+	}
+	for _, holiday := range holidays {
+		calendar.holidays[holiday.HolidayDate.Format(dateLayout)] = holiday
+	}
+
+	return calendar, nil
+}
+
+func (s *CalendarService) ListLocations(ctx context.Context) ([]models.Location, error) {
+	return s.calendarRepo.ListLocations(ctx)
+}
+
+func (s *CalendarService) CreateLocation(ctx context.Context, req models.LocationRequest, createdBy uuid.UUID) (*models.Location, error) {
+	weekendDays, err := normalizeWeekendDays(req.WeekendDays)
+	if err != nil {
+		return nil, err
+	}
+
+	location := &models.Location{
+		Name:        strings.TrimSpace(req.Name),
+		WeekendDays: weekendDays,
+		CreatedBy:   &createdBy,
+	}
+	if location.Name == "" {
+		return nil, ErrLocationNameRequired
+	}
+
+	if err := s.calendarRepo.CreateLocation(ctx, location); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrLocationExists
+		}
+		return nil, err
+	}
+
+	return location, nil
+}
+
+// UpdateLocation renames a location or changes its weekend. Payslips that
+// were already processed keep the working days they were computed with.
+func (s *CalendarService) UpdateLocation(ctx context.Context, id uuid.UUID, req models.LocationRequest, updatedBy uuid.UUID) (*models.Location, error) {
+	location, err := s.calendarRepo.GetLocation(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrLocationNotFound
+		}
+		return nil, err
+	}
+
+	weekendDays, err := normalizeWeekendDays(req.WeekendDays)
+	if err != nil {
+		return nil, err
+	}
+
+	location.Name = strings.TrimSpace(req.Name)
+	location.WeekendDays = weekendDays
+	location.UpdatedBy = &updatedBy
+	if location.Name == "" {
+		return nil, ErrLocationNameRequired
+	}
+
+	if err := s.calendarRepo.UpdateLocation(ctx, location); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrLocationExists
+		}
+		return nil, err
+	}
+
+	return location, nil
+}
+
+func (s *CalendarService) ListHolidays(ctx context.Context, filter models.HolidayFilter) ([]models.Holiday, error) {
+	if filter.To.Before(filter.From) {
+		return nil, ErrInvalidHolidayRange
+	}
+	return s.calendarRepo.ListHolidays(ctx, filter)
+}
+
+func (s *CalendarService) CreateHoliday(ctx context.Context, req models.HolidayRequest, createdBy uuid.UUID) (*models.Holiday, error) {
+	holiday := &models.Holiday{CreatedBy: createdBy}
+	if err := s.applyHolidayRequest(ctx, holiday, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.calendarRepo.CreateHoliday(ctx, holiday); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrHolidayExists
+		}
+		return nil, err
+	}
+
+	return holiday, nil
+}
+
+func (s *CalendarService) UpdateHoliday(ctx context.Context, id uuid.UUID, req models.HolidayRequest, updatedBy uuid.UUID) (*models.Holiday, error) {
+	holiday, err := s.getHoliday(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Both the old and the new date must be outside processed periods.
+	if err := s.ensureDateUnprocessed(ctx, holiday.HolidayDate); err != nil {
+		return nil, err
+	}
+
+	if err := s.applyHolidayRequest(ctx, holiday, req); err != nil {
+		return nil, err
+	}
+	holiday.UpdatedBy = &updatedBy
+
+	if err := s.calendarRepo.UpdateHoliday(ctx, holiday); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrHolidayExists
+		}
+		return nil, err
+	}
+
+	return holiday, nil
+}
+
+func (s *CalendarService) DeleteHoliday(ctx context.Context, id uuid.UUID) error {
+	holiday, err := s.getHoliday(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ensureDateUnprocessed(ctx, holiday.HolidayDate); err != nil {
+		return err
+	}
+
+	if err := s.calendarRepo.DeleteHoliday(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrHolidayNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ImportHolidays adds every day covered by the events of an iCalendar file
+// as a holiday of the given type. Days that already have a holiday for the
+// location are skipped, so re-importing the same file is harmless.
+func (s *CalendarService) ImportHolidays(ctx context.Context, r io.Reader, holidayType string, locationID *uuid.UUID, createdBy uuid.UUID) (*models.HolidayImportResponse, error) {
+	if !isHolidayType(holidayType) {
+		return nil, ErrInvalidHolidayType
+	}
+
+	if locationID != nil {
+		if _, err := s.getLocation(ctx, *locationID); err != nil {
+			return nil, err
+		}
+	}
+
+	events, err := ical.ParseEvents(r)
+	if err != nil {
+		return nil, ErrInvalidICalendar
+	}
+
+	seen := make(map[string]bool)
+	var holidays []models.Holiday
+	for _, event := range events {
+		name := strings.TrimSpace(event.Summary)
+		if name == "" {
+			name = "Holiday"
+		}
+
+		for _, day := range event.Days() {
+			key := day.Format(dateLayout)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if len(seen) > maxImportedHolidays {
+				return nil, ErrTooManyHolidays
+			}
+
+			holidays = append(holidays, models.Holiday{
+				HolidayDate: day,
+				Name:        name,
+				Type:        holidayType,
+				LocationID:  locationID,
+				CreatedBy:   createdBy,
+			})
+		}
+	}
+
+	if len(holidays) == 0 {
+		return nil, ErrNoHolidaysInFile
+	}
+
+	sort.Slice(holidays, func(i, j int) bool {
+		return holidays[i].HolidayDate.Before(holidays[j].HolidayDate)
+	})
+
+	for _, holiday := range holidays {
+		if err := s.ensureDateUnprocessed(ctx, holiday.HolidayDate); err != nil {
+			return nil, err
+		}
+	}
+
+	imported, err := s.calendarRepo.CreateHolidays(ctx, holidays)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.HolidayImportResponse{
+		Imported: imported,
+		Skipped:  len(holidays) - imported,
+	}, nil
+}
+
+func (s *CalendarService) applyHolidayRequest(ctx context.Context, holiday *models.Holiday, req models.HolidayRequest) error {
+	date, err := time.Parse(dateLayout, req.HolidayDate)
+	if err != nil {
+		return ErrInvalidHolidayDate
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return ErrHolidayNameRequired
+	}
+
+	if !isHolidayType(req.Type) {
+		return ErrInvalidHolidayType
+	}
+
+	var locationID *uuid.UUID
+	if req.LocationID != "" {
+		id, err := uuid.Parse(req.LocationID)
+		if err != nil {
+			return ErrLocationNotFound
+		}
+		if _, err := s.getLocation(ctx, id); err != nil {
+			return err
+		}
+		locationID = &id
+	}
+
+	if err := s.ensureDateUnprocessed(ctx, date); err != nil {
+		return err
+	}
+
+	holiday.HolidayDate = date
+	holiday.Name = name
+	holiday.Type = req.Type
+	holiday.LocationID = locationID
+	return nil
+}
+
+// ensureDateUnprocessed rejects calendar changes on days whose payroll has
+// already been computed in any of the periods covering them.
+func (s *CalendarService) ensureDateUnprocessed(ctx context.Context, date time.Time) error {
+	periods, err := s.periodRepo.GetOverlapping(ctx, date, date)
+	if err != nil {
+		return err
+	}
+
+	for _, period := range periods {
+		if period.PayrollProcessed {
+			return ErrHolidayInProcessedPeriod
+		}
+	}
+	return nil
+}
+
+func (s *CalendarService) getLocation(ctx context.Context, id uuid.UUID) (*models.Location, error) {
+	location, err := s.calendarRepo.GetLocation(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrLocationNotFound
+		}
+		return nil, err
+	}
+	return location, nil
+}
+
+func (s *CalendarService) getHoliday(ctx context.Context, id uuid.UUID) (*models.Holiday, error) {
+	holiday, err := s.calendarRepo.GetHoliday(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrHolidayNotFound
+		}
+		return nil, err
+	}
+	return holiday, nil
+}
+
+// normalizeWeekendDays validates, sorts and de-duplicates weekend days. At
+// least one day of the week has to remain a working day.
+func normalizeWeekendDays(days []int) ([]int, error) {
+	set := make(map[int]bool, len(days))
+	for _, day := range days {
+		if day < int(time.Sunday) || day > int(time.Saturday) {
+			return nil, ErrInvalidWeekendDays
+		}
+		set[day] = true
+	}
+
+	if len(set) == 7 {
+		return nil, ErrInvalidWeekendDays
+	}
+
+	normalized := make([]int, 0, len(set))
+	for day := range set {
+		normalized = append(normalized, day)
+	}
+	sort.Ints(normalized)
+	return normalized, nil
+}
+
+func isHolidayType(holidayType string) bool {
+	return holidayType == models.HolidayTypePublic || holidayType == models.HolidayTypeCompanyClosure
+}
+
+// Errors
+var (
+	ErrLocationNotFound         = NewAppError("location not found", 404)
+	ErrLocationExists           = NewAppError("a location with this name already exists", 409)
+	ErrLocationNameRequired     = NewAppError("location name is required", 400)
+	ErrInvalidWeekendDays       = NewAppError("weekend days must be between 0 (Sunday) and 6 (Saturday) and leave at least one working day", 400)
+	ErrHolidayNotFound          = NewAppError("holiday not found", 404)
+	ErrHolidayExists            = NewAppError("a holiday already exists on this date for this location", 409)
+	ErrHolidayNameRequired      = NewAppError("holiday name is required", 400)
+	ErrInvalidHolidayDate       = NewAppError("invalid holiday date format", 400)
+	ErrInvalidHolidayType       = NewAppError("type must be one of: public_holiday, company_closure", 400)
+	ErrInvalidHolidayRange      = NewAppError("to must not be before from", 400)
+	ErrHolidayInProcessedPeriod = NewAppError("cannot change holidays in a period whose payroll has been processed", 409)
+	ErrInvalidICalendar         = NewAppError("invalid iCalendar file", 400)
+	ErrNoHolidaysInFile         = NewAppError("the iCalendar file contains no events", 400)
+	ErrTooManyHolidays          = NewAppError("an import may cover at most 366 days", 400)
+)
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/money"
+)
+
+type ExchangeRateService struct {
+	rateRepo repository.ExchangeRateRepository
+}
+
+func NewExchangeRateService(rateRepo repository.ExchangeRateRepository) *ExchangeRateService {
+	return &ExchangeRateService{
+		rateRepo: rateRepo,
+	}
+}
+
+func (s *ExchangeRateService) List(ctx context.Context, filter models.ExchangeRateFilter) ([]models.ExchangeRate, error) {
+	filter.FromCurrency = strings.ToUpper(filter.FromCurrency)
+	filter.ToCurrency = strings.ToUpper(filter.ToCurrency)
+	return s.rateRepo.List(ctx, filter)
+}
+
+// Create adds a rate. Payslips keep a copy of the rates they were converted
+// with, so rates can be added or corrected for any date.
+func (s *ExchangeRateService) Create(ctx context.Context, req models.ExchangeRateRequest, createdBy uuid.UUID) (*models.ExchangeRate, error) {
+	rate := &models.ExchangeRate{CreatedBy: &createdBy}
+	if err := applyExchangeRateRequest(rate, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.rateRepo.Create(ctx, rate); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrExchangeRateExists
+		}
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+func (s *ExchangeRateService) Update(ctx context.Context, id uuid.UUID, req models.ExchangeRateRequest, updatedBy uuid.UUID) (*models.ExchangeRate, error) {
+	rate, err := s.getRate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyExchangeRateRequest(rate, req); err != nil {
+		return nil, err
+	}
+	rate.UpdatedBy = &updatedBy
+
+	if err := s.rateRepo.Update(ctx, rate); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrExchangeRateExists
+		}
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+func (s *ExchangeRateService) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := s.rateRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrExchangeRateNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *ExchangeRateService) getRate(ctx context.Context, id uuid.UUID) (*models.ExchangeRate, error) {
+	rate, err := s.rateRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrExchangeRateNotFound
+		}
+		return nil, err
+	}
+	return rate, nil
+}
+
+// applyExchangeRateRequest validates req and copies it onto rate.
+func applyExchangeRateRequest(rate *models.ExchangeRate, req models.ExchangeRateRequest) error {
+	from, err := normalizeCurrency(req.FromCurrency)
+	if err != nil {
+		return err
+	}
+	to, err := normalizeCurrency(req.ToCurrency)
+	if err != nil {
+		return err
+	}
+	if from == "" || to == "" {
+		return ErrInvalidCurrency
+	}
+	if from == to {
+		return ErrSameCurrency
+	}
+
+	if !req.Rate.IsPositive() {
+		return ErrInvalidExchangeRate
+	}
+
+	rateDate, err := time.Parse(dateLayout, req.RateDate)
+	if err != nil {
+		return ErrInvalidRateDate
+	}
+
+	rate.FromCurrency = from
+	rate.ToCurrency = to
+	rate.Rate = req.Rate
+	rate.RateDate = rateDate
+	return nil
+}
+
+// normalizeCurrency upper-cases an ISO 4217 code, leaving an empty code
+// empty.
+func normalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code != "" && !money.ValidCurrency(code) {
+		return "", ErrInvalidCurrency
+	}
+	return code, nil
+}
+
+// currencyOrDefault is normalizeCurrency with the home currency for an empty
+// code.
+func currencyOrDefault(code string) (string, error) {
+	code, err := normalizeCurrency(code)
+	if code == "" && err == nil {
+		code = money.DefaultCurrency
+	}
+	return code, err
+}
+
+// rateBook holds the rates in force on one date, looking each currency pair
+// up once.
+type rateBook struct {
+	rateRepo repository.ExchangeRateRepository
+	date     time.Time
+	rates    map[[2]string]*models.ExchangeRate
+}
+
+func newRateBook(rateRepo repository.ExchangeRateRepository, date time.Time) *rateBook {
+	return &rateBook{
+		rateRepo: rateRepo,
+		date:     date,
+		rates:    make(map[[2]string]*models.ExchangeRate),
+	}
+}
+
+// rate returns the rate from one currency to another, or an error naming the
+// missing pair.
+func (b *rateBook) rate(ctx context.Context, from, to string) (*models.ExchangeRate, error) {
+	key := [2]string{from, to}
+	if rate, ok := b.rates[key]; ok {
+		return rate, nil
+	}
+
+	rate, err := b.rateRepo.GetEffective(ctx, from, to, b.date)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, NewAppError(fmt.Sprintf("no exchange rate from %s to %s on or before %s", from, to, b.date.Format(dateLayout)), 422)
+		}
+		return nil, err
+	}
+
+	b.rates[key] = rate
+	return rate, nil
+}
+
+// converter converts amounts into one currency for a single payslip and
+// remembers the rates it used.
+type converter struct {
+	book     *rateBook
+	currency string
+	used     map[string]models.PayslipExchangeRate
+}
+
+func (b *rateBook) converter(currency string) *converter {
+	return &converter{book: b, currency: currency, used: make(map[string]models.PayslipExchangeRate)}
+}
+
+// convert returns amount in the converter's currency. Each amount is
+// converted on its own and rounded half away from zero to the minor unit.
+func (c *converter) convert(ctx context.Context, amount money.Money) (money.Money, error) {
+	if amount.Currency == c.currency {
+		return amount, nil
+	}
+
+	rate, err := c.book.rate(ctx, amount.Currency, c.currency)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	c.used[rate.FromCurrency] = models.PayslipExchangeRate{
+		FromCurrency: rate.FromCurrency,
+		ToCurrency:   rate.ToCurrency,
+		Rate:         rate.Rate,
+		RateDate:     rate.RateDate,
+	}
+	return amount.Convert(rate.Rate, c.currency), nil
+}
+
+// rates lists the rates used so far, ordered by source currency.
+func (c *converter) rates() []models.PayslipExchangeRate {
+	rates := make([]models.PayslipExchangeRate, 0, len(c.used))
+	for _, rate := range c.used {
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].FromCurrency < rates[j].FromCurrency
+	})
+	return rates
+}
+
+// Errors
+var (
+	ErrExchangeRateNotFound = NewAppError("exchange rate not found", 404)
+	ErrExchangeRateExists   = NewAppError("a rate for this currency pair already exists on this date", 409)
+	ErrInvalidCurrency      = NewAppError("currency must be a three-letter ISO 4217 code", 400)
+	ErrSameCurrency         = NewAppError("from and to currencies must differ", 400)
+	ErrInvalidExchangeRate  = NewAppError("rate must be greater than 0", 400)
+	ErrInvalidRateDate      = NewAppError("invalid rate date format", 400)
+)
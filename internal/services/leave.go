@@ -0,0 +1,397 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+type LeaveService struct {
+	leaveRepo       repository.LeaveRepository
+	userRepo        repository.UserRepository
+	periodRepo      repository.AttendancePeriodRepository
+	calendarService *CalendarService
+}
+
+func NewLeaveService(
+	leaveRepo repository.LeaveRepository,
+	userRepo repository.UserRepository,
+	periodRepo repository.AttendancePeriodRepository,
+	calendarService *CalendarService,
+) *LeaveService {
+	return &LeaveService{
+		leaveRepo:       leaveRepo,
+		userRepo:        userRepo,
+		periodRepo:      periodRepo,
+		calendarService: calendarService,
+	}
+}
+
+func (s *LeaveService) ListTypes(ctx context.Context) ([]models.LeaveType, error) {
+	return s.leaveRepo.GetTypes(ctx)
+}
+
+// CurrentYear returns the year it is in the configured time zone.
+func (s *LeaveService) CurrentYear() int {
+	return s.calendarService.Today().Year()
+}
+
+// GetBalances returns the user's balance of every leave type that is limited
+// by one, as accrued today for the current year.
+func (s *LeaveService) GetBalances(ctx context.Context, userID uuid.UUID, year int) ([]models.LeaveBalance, error) {
+	leaveTypes, err := s.leaveRepo.GetTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := []models.LeaveBalance{}
+	for i := range leaveTypes {
+		if leaveTypes[i].AnnualDays == nil {
+			continue
+		}
+
+		balance, err := s.balance(ctx, userID, &leaveTypes[i], year, s.calendarService.Today())
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, *balance)
+	}
+
+	return balances, nil
+}
+
+// SetEntitlement overrides the annual days of a leave type for one user and
+// year.
+func (s *LeaveService) SetEntitlement(ctx context.Context, userID uuid.UUID, leaveTypeCode string, req models.SetLeaveEntitlementRequest, setBy uuid.UUID) (*models.LeaveEntitlement, error) {
+	if req.Year < 2000 || req.Year > 9999 {
+		return nil, ErrInvalidLeaveYear
+	}
+	if req.EntitledDays < 0 || req.EntitledDays > 366 {
+		return nil, ErrInvalidEntitledDays
+	}
+
+	leaveType, err := s.getType(ctx, leaveTypeCode)
+	if err != nil {
+		return nil, err
+	}
+	if leaveType.AnnualDays == nil {
+		return nil, ErrLeaveTypeUnlimited
+	}
+
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	entitlement := &models.LeaveEntitlement{
+		UserID:       userID,
+		LeaveType:    leaveType.Code,
+		Year:         req.Year,
+		EntitledDays: req.EntitledDays,
+		CreatedBy:    setBy,
+	}
+	if err := s.leaveRepo.UpsertEntitlement(ctx, entitlement); err != nil {
+		return nil, err
+	}
+
+	return entitlement, nil
+}
+
+// SubmitRequest requests leave over a date range within one year. Only the
+// working days at the user's location count against the balance, and the
+// days must have been accrued by the end of the leave.
+func (s *LeaveService) SubmitRequest(ctx context.Context, userID uuid.UUID, req models.SubmitLeaveRequest) (*models.LeaveRequest, error) {
+	startDate, err := time.Parse(dateLayout, req.StartDate)
+	if err != nil {
+		return nil, ErrInvalidLeaveDates
+	}
+	endDate, err := time.Parse(dateLayout, req.EndDate)
+	if err != nil {
+		return nil, ErrInvalidLeaveDates
+	}
+	if endDate.Before(startDate) {
+		return nil, ErrInvalidLeaveDates
+	}
+	if startDate.Year() != endDate.Year() {
+		return nil, ErrLeaveSpansYears
+	}
+
+	leaveType, err := s.getType(ctx, req.LeaveType)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureUnprocessed(ctx, startDate, endDate); err != nil {
+		return nil, err
+	}
+
+	calendar, err := s.calendarService.ForUser(ctx, user, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	days := calendar.WorkingDays(startDate, endDate)
+	if days == 0 {
+		return nil, ErrLeaveHasNoWorkingDays
+	}
+
+	request := &models.LeaveRequest{
+		UserID:    userID,
+		LeaveType: leaveType.Code,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Days:      days,
+		Reason:    strings.TrimSpace(req.Reason),
+		CreatedBy: userID,
+	}
+
+	// Overlaps and the balance are checked while the user's other requests
+	// are held off, so two requests at once cannot both pass.
+	err = s.leaveRepo.CreateRequest(ctx, request, func(ctx context.Context) error {
+		overlapping, err := s.leaveRepo.GetActiveRequestsBetween(ctx, userID, startDate, endDate)
+		if err != nil {
+			return err
+		}
+		if len(overlapping) > 0 {
+			return ErrLeaveOverlaps
+		}
+
+		if leaveType.AnnualDays != nil {
+			balance, err := s.balance(ctx, userID, leaveType, startDate.Year(), endDate)
+			if err != nil {
+				return err
+			}
+			if float64(days) > balance.AvailableDays {
+				return ErrInsufficientLeaveBalance
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, mapLeaveWriteError(err)
+	}
+
+	return request, nil
+}
+
+func (s *LeaveService) ListOwnRequests(ctx context.Context, userID uuid.UUID, year int) ([]models.LeaveRequest, error) {
+	return s.leaveRepo.GetRequestsByUser(ctx, userID, year)
+}
+
+// CancelRequest withdraws a pending request, or an approved one that has not
+// started yet.
+func (s *LeaveService) CancelRequest(ctx context.Context, userID, requestID uuid.UUID) (*models.LeaveRequest, error) {
+	request, err := s.getRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.UserID != userID {
+		return nil, ErrLeaveRequestNotFound
+	}
+
+	switch request.Status {
+	case models.LeaveStatusPending:
+	case models.LeaveStatusApproved:
+		if !request.StartDate.After(s.calendarService.Today()) {
+			return nil, ErrLeaveAlreadyStarted
+		}
+	default:
+		return nil, ErrLeaveRequestNotCancellable
+	}
+
+	if err := s.ensureUnprocessed(ctx, request.StartDate, request.EndDate); err != nil {
+		return nil, err
+	}
+
+	request.Status = models.LeaveStatusCancelled
+	request.UpdatedBy = &userID
+	if err := s.leaveRepo.UpdateRequest(ctx, request); err != nil {
+		return nil, mapLeaveWriteError(err)
+	}
+
+	return request, nil
+}
+
+// GetPendingForManager lists the pending leave requests of the manager's
+// direct reports.
+func (s *LeaveService) GetPendingForManager(ctx context.Context, managerID uuid.UUID) ([]models.LeaveRequest, error) {
+	return s.leaveRepo.GetPendingRequestsByManager(ctx, managerID)
+}
+
+// Review approves or rejects a pending leave request. Only the employee's
+// direct manager may review it, and rejections need a reason.
+func (s *LeaveService) Review(ctx context.Context, reviewerID, requestID uuid.UUID, approve bool, reason string) (*models.LeaveRequest, error) {
+	if !approve && reason == "" {
+		return nil, ErrRejectionReasonRequired
+	}
+
+	request, err := s.getRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	employee, err := s.userRepo.GetByID(ctx, request.UserID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if employee == nil || employee.ManagerID == nil || *employee.ManagerID != reviewerID {
+		return nil, ErrNotEmployeeManager
+	}
+
+	if request.Status != models.LeaveStatusPending {
+		return nil, ErrLeaveRequestAlreadyReviewed
+	}
+
+	if err := s.ensureUnprocessed(ctx, request.StartDate, request.EndDate); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	request.Status = models.LeaveStatusRejected
+	if approve {
+		request.Status = models.LeaveStatusApproved
+	}
+	request.ReviewedBy = &reviewerID
+	request.ReviewedAt = &now
+	request.ReviewReason = reason
+	request.UpdatedBy = &reviewerID
+
+	if err := s.leaveRepo.UpdateRequest(ctx, request); err != nil {
+		return nil, mapLeaveWriteError(err)
+	}
+
+	return request, nil
+}
+
+// balance computes a user's balance of a leave type for a year, accrued up to
+// asOf.
+func (s *LeaveService) balance(ctx context.Context, userID uuid.UUID, leaveType *models.LeaveType, year int, asOf time.Time) (*models.LeaveBalance, error) {
+	entitled := *leaveType.AnnualDays
+	entitlement, err := s.leaveRepo.GetEntitlement(ctx, userID, leaveType.Code, year)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if entitlement != nil {
+		entitled = entitlement.EntitledDays
+	}
+
+	approved, pending, err := s.leaveRepo.GetUsage(ctx, userID, leaveType.Code, year)
+	if err != nil {
+		return nil, err
+	}
+
+	accrued := accruedLeaveDays(leaveType.Accrual, entitled, year, asOf)
+	return &models.LeaveBalance{
+		LeaveType:     leaveType.Code,
+		Year:          year,
+		EntitledDays:  entitled,
+		AccruedDays:   accrued,
+		UsedDays:      float64(approved),
+		PendingDays:   float64(pending),
+		AvailableDays: accrued - float64(approved+pending),
+	}, nil
+}
+
+// ensureUnprocessed rejects leave changes that overlap a period whose payroll
+// has already been computed.
+func (s *LeaveService) ensureUnprocessed(ctx context.Context, start, end time.Time) error {
+	periods, err := s.periodRepo.GetOverlapping(ctx, start, end)
+	if err != nil {
+		return err
+	}
+
+	for _, period := range periods {
+		if period.PayrollProcessed {
+			return ErrPeriodProcessed
+		}
+	}
+	return nil
+}
+
+// mapLeaveWriteError reports the database refusing a write that overlaps a
+// payslip stored since ensureUnprocessed ran as ErrPeriodProcessed.
+func mapLeaveWriteError(err error) error {
+	if errors.Is(err, repository.ErrPeriodLocked) {
+		return ErrPeriodProcessed
+	}
+	return err
+}
+
+func (s *LeaveService) getType(ctx context.Context, code string) (*models.LeaveType, error) {
+	leaveType, err := s.leaveRepo.GetType(ctx, code)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrLeaveTypeNotFound
+		}
+		return nil, err
+	}
+	return leaveType, nil
+}
+
+func (s *LeaveService) getUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *LeaveService) getRequest(ctx context.Context, id uuid.UUID) (*models.LeaveRequest, error) {
+	request, err := s.leaveRepo.GetRequestByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrLeaveRequestNotFound
+		}
+		return nil, err
+	}
+	return request, nil
+}
+
+// accruedLeaveDays returns how much of a year's entitlement has accrued by
+// asOf. Monthly accrual grants a twelfth at the start of each month, rounded
+// down to two decimals so a balance never runs ahead of the entitlement.
+func accruedLeaveDays(accrual string, entitled float64, year int, asOf time.Time) float64 {
+	if accrual != models.LeaveAccrualMonthly {
+		return entitled
+	}
+
+	months := 12
+	switch {
+	case asOf.Year() < year:
+		months = 0
+	case asOf.Year() == year:
+		months = int(asOf.Month())
+	}
+
+	return math.Floor(entitled*float64(months)/12*100) / 100
+}
+
+// Errors
+var (
+	ErrLeaveTypeNotFound           = NewAppError("leave type not found", 404)
+	ErrLeaveTypeUnlimited          = NewAppError("this leave type is not limited by a balance", 400)
+	ErrLeaveRequestNotFound        = NewAppError("leave request not found", 404)
+	ErrLeaveRequestAlreadyReviewed = NewAppError("leave request has already been reviewed", 409)
+	ErrLeaveRequestNotCancellable  = NewAppError("only pending or approved leave requests can be cancelled", 409)
+	ErrLeaveAlreadyStarted         = NewAppError("approved leave cannot be cancelled once it has started", 409)
+	ErrInvalidLeaveDates           = NewAppError("start and end dates must be YYYY-MM-DD with the end on or after the start", 400)
+	ErrLeaveSpansYears             = NewAppError("leave cannot span two calendar years; submit one request per year", 400)
+	ErrLeaveOverlaps               = NewAppError("leave overlaps another pending or approved request", 409)
+	ErrLeaveHasNoWorkingDays       = NewAppError("leave does not cover any working days", 400)
+	ErrInsufficientLeaveBalance    = NewAppError("insufficient leave balance", 409)
+	ErrInvalidLeaveYear            = NewAppError("invalid year", 400)
+	ErrInvalidEntitledDays         = NewAppError("entitled days must be between 0 and 366", 400)
+)
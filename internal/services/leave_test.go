@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+)
+
+func TestAccruedLeaveDays(t *testing.T) {
+	on := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		accrual  string
+		entitled float64
+		year     int
+		asOf     time.Time
+		want     float64
+	}{
+		{"upfront grants everything at once", models.LeaveAccrualUpfront, 12, 2025, on(2025, 1, 1), 12},
+		{"upfront before the year", models.LeaveAccrualUpfront, 12, 2025, on(2024, 6, 1), 12},
+		{"monthly on the first day of the year", models.LeaveAccrualMonthly, 12, 2025, on(2025, 1, 1), 1},
+		{"monthly late in January", models.LeaveAccrualMonthly, 12, 2025, on(2025, 1, 31), 1},
+		{"monthly at the start of March", models.LeaveAccrualMonthly, 12, 2025, on(2025, 3, 1), 3},
+		{"monthly in December", models.LeaveAccrualMonthly, 12, 2025, on(2025, 12, 31), 12},
+		{"monthly before the year", models.LeaveAccrualMonthly, 12, 2025, on(2024, 12, 31), 0},
+		{"monthly after the year", models.LeaveAccrualMonthly, 12, 2025, on(2026, 1, 1), 12},
+		{"monthly rounds down", models.LeaveAccrualMonthly, 14, 2025, on(2025, 1, 15), 1.16},
+		{"monthly rounds down mid-year", models.LeaveAccrualMonthly, 10, 2025, on(2025, 7, 1), 5.83},
+		{"monthly exact half year", models.LeaveAccrualMonthly, 14, 2025, on(2025, 6, 30), 7},
+		{"monthly never exceeds the entitlement", models.LeaveAccrualMonthly, 14, 2025, on(2025, 12, 1), 14},
+		{"monthly without entitlement", models.LeaveAccrualMonthly, 0, 2025, on(2025, 6, 1), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := accruedLeaveDays(tt.accrual, tt.entitled, tt.year, tt.asOf)
+			if got != tt.want {
+				t.Errorf("accruedLeaveDays(%s, %v, %d, %s) = %v, want %v",
+					tt.accrual, tt.entitled, tt.year, tt.asOf.Format(dateLayout), got, tt.want)
+			}
+		})
+	}
+}
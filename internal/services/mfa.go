@@ -0,0 +1,393 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/totp"
+)
+
+const (
+	// mfaChallengeTTL is how long the second step of a login stays open.
+	mfaChallengeTTL   = 5 * time.Minute
+	recoveryCodeCount = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// requiresMFA reports whether the user's role can process payroll, which
+// makes a second factor mandatory.
+func (s *AuthService) requiresMFA(ctx context.Context, user *models.User) (bool, error) {
+	permissions, err := s.roleRepo.GetPermissions(ctx, user.Role)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range permissions {
+		if p == models.PermPayrollRun {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// startMFA opens the second step of a login for user.
+func (s *AuthService) startMFA(ctx context.Context, user *models.User, purpose string) (*models.LoginResponse, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.mfaRepo.CreateChallenge(ctx, &models.MFAChallenge{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(mfaChallengeTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		MFARequired:           true,
+		MFAEnrollmentRequired: purpose == models.MFAPurposeEnroll,
+		MFAToken:              token,
+	}, nil
+}
+
+// challengeUser returns the open challenge for an MFA token and its user.
+func (s *AuthService) challengeUser(ctx context.Context, mfaToken, purpose string) (*models.MFAChallenge, *models.User, error) {
+	challenge, err := s.mfaRepo.GetChallenge(ctx, hashToken(mfaToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrInvalidMFAToken
+		}
+		return nil, nil, err
+	}
+	if challenge.Purpose != purpose {
+		return nil, nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrInvalidMFAToken
+		}
+		return nil, nil, err
+	}
+
+	return challenge, user, nil
+}
+
+// VerifyMFA finishes a login with a TOTP or recovery code. Wrong codes count
+// as failed logins.
+func (s *AuthService) VerifyMFA(ctx context.Context, mfaToken, code, clientIP, userAgent string) (*models.LoginResponse, error) {
+	challenge, user, err := s.challengeUser(ctx, mfaToken, models.MFAPurposeVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := &models.LoginHistory{UserID: &user.ID, Username: user.Username, IPAddress: clientIP, UserAgent: userAgent}
+	subjects := loginSubjects(user.Username, clientIP)
+	if err := s.checkLocked(ctx, subjects, attempt); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.checkSecondFactor(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.recordFailure(ctx, subjects, attempt, models.LoginFailureInvalidMFACode); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidMFACode
+	}
+
+	if err := s.useChallenge(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(ctx, user, attempt)
+}
+
+// SetupMFA starts enrolment for a user who must enrol before their login can
+// finish.
+func (s *AuthService) SetupMFA(ctx context.Context, mfaToken string) (*models.MFAEnrollmentResponse, error) {
+	_, user, err := s.challengeUser(ctx, mfaToken, models.MFAPurposeEnroll)
+	if err != nil {
+		return nil, err
+	}
+	return s.beginEnrollment(ctx, user)
+}
+
+// ActivateMFA confirms the enrolment SetupMFA started and finishes the login.
+// The response carries the new recovery codes.
+func (s *AuthService) ActivateMFA(ctx context.Context, mfaToken, code, clientIP, userAgent string) (*models.LoginResponse, error) {
+	challenge, user, err := s.challengeUser(ctx, mfaToken, models.MFAPurposeEnroll)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := &models.LoginHistory{UserID: &user.ID, Username: user.Username, IPAddress: clientIP, UserAgent: userAgent}
+	subjects := loginSubjects(user.Username, clientIP)
+	if err := s.checkLocked(ctx, subjects, attempt); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.confirmEnrollment(ctx, user, code)
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			if err := s.recordFailure(ctx, subjects, attempt, models.LoginFailureInvalidMFACode); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	if err := s.useChallenge(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.completeLogin(ctx, user, attempt)
+	if err != nil {
+		return nil, err
+	}
+	resp.RecoveryCodes = codes
+	return resp, nil
+}
+
+func (s *AuthService) useChallenge(ctx context.Context, challenge *models.MFAChallenge) error {
+	if err := s.mfaRepo.UseChallenge(ctx, challenge.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidMFAToken
+		}
+		return err
+	}
+	return nil
+}
+
+// BeginEnrollment starts TOTP enrolment for a signed-in user. Starting again
+// before confirming replaces the secret.
+func (s *AuthService) BeginEnrollment(ctx context.Context, userID uuid.UUID) (*models.MFAEnrollmentResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.beginEnrollment(ctx, user)
+}
+
+func (s *AuthService) beginEnrollment(ctx context.Context, user *models.User) (*models.MFAEnrollmentResponse, error) {
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaRepo.SetPendingSecret(ctx, user.ID, secret); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	return &models.MFAEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.mfaIssuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmEnrollment turns on MFA once the user proves their authenticator
+// works, and returns their recovery codes.
+func (s *AuthService) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) (*models.RecoveryCodesResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.confirmEnrollment(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *AuthService) confirmEnrollment(ctx context.Context, user *models.User, code string) ([]string, error) {
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.MFASecret == nil {
+		return nil, ErrMFAEnrollmentNotStarted
+	}
+
+	step, ok := totp.Validate(*user.MFASecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaRepo.Enable(ctx, user.ID, step, hashes); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableMFA turns off MFA after checking a current code. Users whose role
+// requires MFA cannot turn it off.
+func (s *AuthService) DisableMFA(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := s.enrolledUser(ctx, userID, code)
+	if err != nil {
+		return err
+	}
+
+	required, err := s.requiresMFA(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequiredForRole
+	}
+
+	return s.mfaRepo.Disable(ctx, user.ID)
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a
+// current code.
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) (*models.RecoveryCodesResponse, error) {
+	user, err := s.enrolledUser(ctx, userID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// enrolledUser returns a user with MFA enabled once code checks out. Wrong
+// codes count against the username like failed logins, so a stolen access
+// token cannot be used to guess them.
+func (s *AuthService) enrolledUser(ctx context.Context, userID uuid.UUID, code string) (*models.User, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.MFAEnabled {
+		return nil, ErrMFANotEnabled
+	}
+
+	attempt := &models.LoginHistory{UserID: &user.ID, Username: user.Username}
+	subjects := [][2]string{{models.LoginScopeUsername, strings.ToLower(user.Username)}}
+	if err := s.checkLocked(ctx, subjects, attempt); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.checkSecondFactor(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.recordFailure(ctx, subjects, attempt, models.LoginFailureInvalidMFACode); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidMFACode
+	}
+
+	return user, nil
+}
+
+func (s *AuthService) getUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// checkSecondFactor accepts a TOTP code not used before, or an unused
+// recovery code, using it up.
+func (s *AuthService) checkSecondFactor(ctx context.Context, user *models.User, code string) (bool, error) {
+	if !user.MFAEnabled || user.MFASecret == nil {
+		return false, nil
+	}
+
+	if step, ok := totp.Validate(*user.MFASecret, code, time.Now()); ok {
+		return usedUp(s.mfaRepo.UseStep(ctx, user.ID, step))
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return false, nil
+	}
+	return usedUp(s.mfaRepo.UseRecoveryCode(ctx, user.ID, hashToken(normalized)))
+}
+
+// usedUp maps the result of using up a code: ErrNotFound means it was
+// already used.
+func usedUp(err error) (bool, error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// newRecoveryCodes returns fresh recovery codes, formatted as xxxxx-xxxxx,
+// and their hashes.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode drops the separator, spaces and case so codes can be
+// typed loosely.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// Errors
+var (
+	ErrInvalidMFAToken         = NewAppError("invalid or expired MFA token", 401)
+	ErrInvalidMFACode          = NewAppError("invalid authentication code", 401)
+	ErrMFAEnrollmentRequired   = NewAppError("your role requires two-factor authentication; log in again to enrol", 401)
+	ErrMFAAlreadyEnabled       = NewAppError("two-factor authentication is already enabled", 409)
+	ErrMFANotEnabled           = NewAppError("two-factor authentication is not enabled", 409)
+	ErrMFAEnrollmentNotStarted = NewAppError("start two-factor enrolment first", 409)
+	ErrMFARequiredForRole      = NewAppError("your role requires two-factor authentication", 403)
+)
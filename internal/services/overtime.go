@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+const (
+	// maxOvertimeHoursPerDay caps overtime after a regular working day.
+	maxOvertimeHoursPerDay = 3
+	// maxOvertimeHoursPerDayOff caps overtime on weekends and holidays, where
+	// every hour worked is overtime.
+	maxOvertimeHoursPerDayOff = workingHoursPerDay
+)
+
+type OvertimeService struct {
+	overtimeRepo    repository.OvertimeRepository
+	attendanceRepo  repository.AttendanceRepository
+	periodRepo      repository.AttendancePeriodRepository
+	userRepo        repository.UserRepository
+	calendarService *CalendarService
+}
+
+func NewOvertimeService(
+	overtimeRepo repository.OvertimeRepository,
+	attendanceRepo repository.AttendanceRepository,
+	periodRepo repository.AttendancePeriodRepository,
+	userRepo repository.UserRepository,
+	calendarService *CalendarService,
+) *OvertimeService {
+	return &OvertimeService{
+		overtimeRepo:    overtimeRepo,
+		attendanceRepo:  attendanceRepo,
+		periodRepo:      periodRepo,
+		userRepo:        userRepo,
+		calendarService: calendarService,
+	}
+}
+
+// SubmitOvertime records the overtime for a day, replacing any earlier
+// submission for the same date. A replaced submission goes back to pending
+// and has to be reviewed again.
+//
+// On working days overtime follows the regular shift, so the user must have
+// checked out first and may claim up to 3 hours. On weekends and holidays at
+// the user's location there is no shift to check into, and up to a full
+// working day may be claimed.
+func (s *OvertimeService) SubmitOvertime(ctx context.Context, userID uuid.UUID, req models.SubmitOvertimeRequest, ipAddress string) (*models.Overtime, error) {
+	overtimeDate, err := time.Parse(dateLayout, req.OvertimeDate)
+	if err != nil {
+		return nil, ErrInvalidOvertimeDate
+	}
+
+	period, err := resolvePeriod(ctx, s.periodRepo, req.AttendancePeriodID, overtimeDate, s.calendarService.Today())
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar, err := s.calendarService.ForUser(ctx, user, overtimeDate, overtimeDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if calendar.IsWorkingDay(overtimeDate) {
+		if req.HoursWorked <= 0 || req.HoursWorked > maxOvertimeHoursPerDay {
+			return nil, ErrInvalidOvertimeHours
+		}
+
+		attendance, err := s.attendanceRepo.GetByUserAndDate(ctx, userID, overtimeDate)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		if attendance == nil || attendance.CheckOutTime == nil {
+			return nil, ErrOvertimeRequiresCheckOut
+		}
+	} else if req.HoursWorked <= 0 || req.HoursWorked > maxOvertimeHoursPerDayOff {
+		return nil, ErrInvalidDayOffOvertimeHours
+	}
+
+	existing, err := s.overtimeRepo.GetByUserAndDate(ctx, userID, overtimeDate)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	if existing != nil {
+		if existing.AttendancePeriodID != period.ID {
+			if _, err := openPeriod(ctx, s.periodRepo, existing.AttendancePeriodID); err != nil {
+				return nil, err
+			}
+		}
+
+		fromStatus := existing.Status
+		existing.HoursWorked = req.HoursWorked
+		existing.Description = req.Description
+		existing.Status = models.OvertimeStatusPending
+		existing.ReviewedBy = nil
+		existing.ReviewedAt = nil
+		existing.ReviewReason = ""
+		existing.UpdatedBy = &userID
+		if err := s.overtimeRepo.Update(ctx, existing, fromStatus); err != nil {
+			if errors.Is(err, repository.ErrStatusChanged) {
+				return nil, ErrOvertimeChanged
+			}
+			return nil, mapWriteError(err)
+		}
+		return existing, nil
+	}
+
+	overtime := &models.Overtime{
+		UserID:             userID,
+		AttendancePeriodID: period.ID,
+		OvertimeDate:       overtimeDate,
+		HoursWorked:        req.HoursWorked,
+		Description:        req.Description,
+		IPAddress:          ipAddress,
+		CreatedBy:          userID,
+	}
+
+	if err := s.overtimeRepo.Create(ctx, overtime); err != nil {
+		return nil, mapWriteError(err)
+	}
+
+	return overtime, nil
+}
+
+// GetPendingForManager lists the pending overtime of the manager's direct reports.
+func (s *OvertimeService) GetPendingForManager(ctx context.Context, managerID uuid.UUID) ([]models.Overtime, error) {
+	return s.overtimeRepo.GetPendingByManager(ctx, managerID)
+}
+
+// Review approves or rejects a pending overtime entry. Only the employee's
+// direct manager may review it, and rejections need a reason.
+func (s *OvertimeService) Review(ctx context.Context, reviewerID, overtimeID uuid.UUID, approve bool, reason string) (*models.Overtime, error) {
+	if !approve && reason == "" {
+		return nil, ErrRejectionReasonRequired
+	}
+
+	overtime, err := s.overtimeRepo.GetByID(ctx, overtimeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrOvertimeNotFound
+		}
+		return nil, err
+	}
+
+	employee, err := s.userRepo.GetByID(ctx, overtime.UserID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if employee == nil || employee.ManagerID == nil || *employee.ManagerID != reviewerID {
+		return nil, ErrNotEmployeeManager
+	}
+
+	if overtime.Status != models.OvertimeStatusPending {
+		return nil, ErrOvertimeAlreadyReviewed
+	}
+
+	if _, err := openPeriod(ctx, s.periodRepo, overtime.AttendancePeriodID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	overtime.Status = models.OvertimeStatusRejected
+	if approve {
+		overtime.Status = models.OvertimeStatusApproved
+	}
+	overtime.ReviewedBy = &reviewerID
+	overtime.ReviewedAt = &now
+	overtime.ReviewReason = reason
+	overtime.UpdatedBy = &reviewerID
+
+	if err := s.overtimeRepo.Update(ctx, overtime, models.OvertimeStatusPending); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, ErrOvertimeAlreadyReviewed
+		}
+		return nil, mapWriteError(err)
+	}
+
+	return overtime, nil
+}
+
+// Errors
+var (
+	ErrOvertimeNotFound           = NewAppError("overtime not found", 404)
+	ErrOvertimeAlreadyReviewed    = NewAppError("overtime has already been reviewed", 409)
+	ErrOvertimeChanged            = NewAppError("overtime was reviewed while you were resubmitting it; reload and try again", 409)
+	ErrNotEmployeeManager         = NewAppError("only the employee's manager can review this submission", 403)
+	ErrRejectionReasonRequired    = NewAppError("a reason is required when rejecting", 400)
+	ErrInvalidOvertimeHours       = NewAppError("overtime hours must be between 0 and 3", 400)
+	ErrInvalidDayOffOvertimeHours = NewAppError("overtime hours on a day off must be between 0 and 8", 400)
+	ErrInvalidOvertimeDate        = NewAppError("invalid overtime date format", 400)
+	ErrOvertimeRequiresCheckOut   = NewAppError("overtime on a working day can only be submitted after checking out", 409)
+)
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// maxPasswordBytes is as much of a password as bcrypt reads.
+	maxPasswordBytes = 72
+	// passwordResetTTL is how long an admin-issued reset token lasts, and
+	// passwordChangeTTL how long login gives a user to replace a password
+	// they must change.
+	passwordResetTTL  = 24 * time.Hour
+	passwordChangeTTL = 10 * time.Minute
+)
+
+// PasswordPolicy decides which new passwords are acceptable and hashes them.
+type PasswordPolicy struct {
+	passwordRepo repository.PasswordRepository
+	minLength    int
+	historySize  int
+	breached     map[string]struct{}
+}
+
+// NewPasswordPolicy loads the breached-password list from breachedFile, one
+// password per line, if it is set. historySize is how many recent passwords
+// cannot be reused.
+func NewPasswordPolicy(passwordRepo repository.PasswordRepository, minLength, historySize int, breachedFile string) (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{
+		passwordRepo: passwordRepo,
+		minLength:    minLength,
+		historySize:  historySize,
+		breached:     make(map[string]struct{}),
+	}
+
+	if breachedFile == "" {
+		return policy, nil
+	}
+
+	f, err := os.Open(breachedFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			policy.breached[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+
+	return policy, nil
+}
+
+// Hash checks password against the policy for user and returns its hash.
+// user.ID may be unset for a user not created yet.
+func (p *PasswordPolicy) Hash(ctx context.Context, user *models.User, password string) (string, error) {
+	if utf8.RuneCountInString(password) < p.minLength {
+		return "", NewAppError(fmt.Sprintf("password must be at least %d characters", p.minLength), 400)
+	}
+	if len(password) > maxPasswordBytes {
+		return "", ErrPasswordTooLong
+	}
+	if strings.EqualFold(password, user.Username) {
+		return "", ErrPasswordMatchesUsername
+	}
+	// Breached passwords are matched ignoring case, so capitalising one does
+	// not get it through.
+	if _, ok := p.breached[strings.ToLower(password)]; ok {
+		return "", ErrPasswordBreached
+	}
+
+	if user.ID != uuid.Nil && p.historySize > 0 {
+		reused, err := p.reused(ctx, user.ID, password)
+		if err != nil {
+			return "", err
+		}
+		if reused {
+			return "", NewAppError(fmt.Sprintf("password must differ from your last %d passwords", p.historySize), 400)
+		}
+	}
+
+	return utils.HashPassword(password)
+}
+
+// reused reports whether password matches one of the user's recent ones. The
+// comparisons are slow by design, so they run concurrently.
+func (p *PasswordPolicy) reused(ctx context.Context, userID uuid.UUID, password string) (bool, error) {
+	hashes, err := p.passwordRepo.RecentHashes(ctx, userID, p.historySize)
+	if err != nil {
+		return false, err
+	}
+
+	matches := make(chan bool, len(hashes))
+	for _, hash := range hashes {
+		go func(hash string) {
+			matches <- bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+		}(hash)
+	}
+	for range hashes {
+		if <-matches {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// issueResetToken stores a new reset token for the user, voiding older ones,
+// and returns it.
+func issueResetToken(ctx context.Context, passwordRepo repository.PasswordRepository, userID uuid.UUID, createdBy *uuid.UUID, ttl time.Duration) (*models.PasswordResetResponse, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	reset := &models.PasswordResetToken{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+		CreatedBy: createdBy,
+	}
+	if err := passwordRepo.CreateResetToken(ctx, reset); err != nil {
+		return nil, err
+	}
+
+	return &models.PasswordResetResponse{ResetToken: token, ExpiresAt: reset.ExpiresAt}, nil
+}
+
+// ChangePassword replaces the signed-in user's password after checking the
+// current one, and logs out their other sessions. Wrong current passwords
+// count as failed logins.
+func (s *AuthService) ChangePassword(ctx context.Context, claims *Claims, currentPassword, newPassword, clientIP, userAgent string) error {
+	user, err := s.getUser(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	attempt := &models.LoginHistory{UserID: &user.ID, Username: user.Username, IPAddress: clientIP, UserAgent: userAgent}
+	subjects := loginSubjects(user.Username, clientIP)
+	if err := s.checkLocked(ctx, subjects, attempt); err != nil {
+		return err
+	}
+
+	if !s.checkPassword(currentPassword, []byte(user.PasswordHash)) {
+		if err := s.recordFailure(ctx, subjects, attempt, models.LoginFailureInvalidCredentials); err != nil {
+			return err
+		}
+		return ErrWrongCurrentPassword
+	}
+
+	hash, err := s.passwordPolicy.Hash(ctx, user, newPassword)
+	if err != nil {
+		return err
+	}
+
+	jti, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	sessionID, err := s.tokenRepo.GetSessionIDByAccessJTI(ctx, jti)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+
+	return s.passwordRepo.ChangePassword(ctx, user.ID, hash, sessionID)
+}
+
+// ResetPassword sets a new password with a reset token from an admin or from
+// a login that required a change. It logs out every session and lifts any
+// lockout on the username; the user then logs in with the new password.
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	reset, err := s.passwordRepo.GetResetToken(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, reset.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	// The policy is checked before the token is used up, so a rejected
+	// password can be retried.
+	hash, err := s.passwordPolicy.Hash(ctx, user, newPassword)
+	if err != nil {
+		return err
+	}
+
+	err = s.passwordRepo.ResetPassword(ctx, reset.ID, user.ID, hash, strings.ToLower(user.Username))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	return nil
+}
+
+// rehashIfNeeded rehashes a just-verified password whose hash was made with
+// a different bcrypt cost than the current one.
+func (s *AuthService) rehashIfNeeded(ctx context.Context, user *models.User, password string) error {
+	cost, err := bcrypt.Cost([]byte(user.PasswordHash))
+	if err != nil || cost == utils.PasswordHashCost {
+		return nil
+	}
+
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.userRepo.RehashPassword(ctx, user.ID, hash)
+}
+
+// Errors
+var (
+	ErrPasswordTooLong         = NewAppError(fmt.Sprintf("password must be at most %d bytes", maxPasswordBytes), 400)
+	ErrPasswordMatchesUsername = NewAppError("password must not match the username", 400)
+	ErrPasswordBreached        = NewAppError("password appears in a list of breached passwords; choose another", 400)
+	ErrWrongCurrentPassword    = NewAppError("current password is incorrect", 403)
+	ErrInvalidResetToken       = NewAppError("invalid or expired password reset token", 400)
+)
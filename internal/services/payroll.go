@@ -0,0 +1,430 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/money"
+)
+
+const (
+	workingHoursPerDay = 8
+	overtimeMultiplier = 2
+)
+
+type PayrollService struct {
+	userRepo          repository.UserRepository
+	periodRepo        repository.AttendancePeriodRepository
+	attendanceRepo    repository.AttendanceRepository
+	overtimeRepo      repository.OvertimeRepository
+	reimbursementRepo repository.ReimbursementRepository
+	payslipRepo       repository.PayslipRepository
+	leaveRepo         repository.LeaveRepository
+	salaryRepo        repository.SalaryRepository
+	rateRepo          repository.ExchangeRateRepository
+	calendarService   *CalendarService
+}
+
+func NewPayrollService(
+	userRepo repository.UserRepository,
+	periodRepo repository.AttendancePeriodRepository,
+	attendanceRepo repository.AttendanceRepository,
+	overtimeRepo repository.OvertimeRepository,
+	reimbursementRepo repository.ReimbursementRepository,
+	payslipRepo repository.PayslipRepository,
+	leaveRepo repository.LeaveRepository,
+	salaryRepo repository.SalaryRepository,
+	rateRepo repository.ExchangeRateRepository,
+	calendarService *CalendarService,
+) *PayrollService {
+	return &PayrollService{
+		userRepo:          userRepo,
+		periodRepo:        periodRepo,
+		attendanceRepo:    attendanceRepo,
+		overtimeRepo:      overtimeRepo,
+		reimbursementRepo: reimbursementRepo,
+		payslipRepo:       payslipRepo,
+		leaveRepo:         leaveRepo,
+		salaryRepo:        salaryRepo,
+		rateRepo:          rateRepo,
+		calendarService:   calendarService,
+	}
+}
+
+// RunPayroll computes payslips for every user with a salary in force during
+// the period who is active or was deactivated after working in it, and marks
+// the period as processed. A period can only be
+// processed once. Working days follow the calendar of each user's location,
+// approved paid leave counts as attended, and each day is paid at the salary
+// in force that day. Payslips are in the currency of the salary in force at
+// the end of the period; other amounts are converted at the rates in force on
+// that date.
+func (s *PayrollService) RunPayroll(ctx context.Context, periodID, processedBy uuid.UUID) (*models.PayrollRunResponse, error) {
+	period, err := s.periodRepo.GetByID(ctx, periodID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPeriodNotFound
+		}
+		return nil, err
+	}
+
+	if period.PayrollProcessed {
+		return nil, ErrPayrollAlreadyProcessed
+	}
+
+	// The payslips are computed once the period is locked, so attendance,
+	// overtime, reimbursements, salaries and leave cannot change between
+	// being read and the payslips being stored.
+	var skipped []models.PayrollSkippedUser
+	payslips, err := s.payslipRepo.ProcessPeriod(ctx, period.ID, processedBy, func(ctx context.Context) ([]models.Payslip, error) {
+		calculated, skippedUsers, err := s.calculatePayslips(ctx, period)
+		skipped = skippedUsers
+		return calculated, err
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrPeriodAlreadyProcessed) {
+			return nil, ErrPayrollAlreadyProcessed
+		}
+		return nil, mapWriteError(err)
+	}
+
+	totals := make(map[string]money.Money)
+	for _, payslip := range payslips {
+		totals[payslip.Currency] = totals[payslip.Currency].Add(payslip.TakeHomePay)
+	}
+
+	period, err = s.periodRepo.GetByID(ctx, periodID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PayrollRunResponse{
+		Period:           *period,
+		PayslipCount:     len(payslips),
+		TotalTakeHomePay: totals,
+		SkippedUsers:     skipped,
+	}, nil
+}
+
+// calculatePayslips computes the payslips of every user with a salary in
+// force at the end of the period who is active or was paid for work in it.
+// Users whose calendar has no working days
+// in the period are skipped and returned alongside, so that one location's
+// calendar does not hold up everyone else's pay.
+func (s *PayrollService) calculatePayslips(ctx context.Context, period *models.AttendancePeriod) ([]models.Payslip, []models.PayrollSkippedUser, error) {
+	users, err := s.userRepo.GetPayableInPeriod(ctx, period.ID, period.StartDate, period.EndDate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Users at the same location share a calendar.
+	calendars := make(map[uuid.UUID]*Calendar)
+	rates := newRateBook(s.rateRepo, period.EndDate)
+
+	payslips := []models.Payslip{}
+	skipped := []models.PayrollSkippedUser{}
+	for i := range users {
+		history, err := s.salaryRepo.GetByUser(ctx, users[i].ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := salaryOn(history, period.EndDate); !ok {
+			continue
+		}
+
+		var locationID uuid.UUID
+		if users[i].LocationID != nil {
+			locationID = *users[i].LocationID
+		}
+		calendar, ok := calendars[locationID]
+		if !ok {
+			calendar, err = s.calendarService.ForUser(ctx, &users[i], period.StartDate, period.EndDate)
+			if err != nil {
+				return nil, nil, err
+			}
+			calendars[locationID] = calendar
+		}
+
+		payslip, err := s.calculatePayslip(ctx, period, &users[i], calendar, history, rates)
+		if errors.Is(err, ErrNoWorkingDays) {
+			skipped = append(skipped, models.PayrollSkippedUser{
+				UserID:   users[i].ID,
+				Username: users[i].Username,
+				Reason:   err.Error(),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		payslips = append(payslips, *payslip)
+	}
+
+	return payslips, skipped, nil
+}
+
+// GetPayslip returns the itemised payslip of a user for a processed period.
+func (s *PayrollService) GetPayslip(ctx context.Context, userID, periodID uuid.UUID) (*models.PayslipResponse, error) {
+	period, err := s.periodRepo.GetByID(ctx, periodID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPeriodNotFound
+		}
+		return nil, err
+	}
+
+	if !period.PayrollProcessed {
+		return nil, ErrPayrollNotProcessed
+	}
+
+	payslip, err := s.payslipRepo.GetByUserAndPeriod(ctx, userID, periodID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPayslipNotFound
+		}
+		return nil, err
+	}
+
+	overtimes, err := s.overtimeRepo.GetByUserAndPeriod(ctx, userID, periodID)
+	if err != nil {
+		return nil, err
+	}
+
+	reimbursements, err := s.reimbursementRepo.GetByUserAndPeriod(ctx, userID, periodID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.PayslipResponse{
+		Period:             *period,
+		WorkingDays:        payslip.WorkingDays,
+		AttendanceDays:     payslip.AttendanceDays,
+		PaidLeaveDays:      payslip.PaidLeaveDays,
+		Currency:           payslip.Currency,
+		BaseSalary:         payslip.BaseSalary,
+		ProratedSalary:     payslip.ProratedSalary,
+		OvertimeHours:      payslip.OvertimeHours,
+		OvertimePay:        payslip.OvertimePay,
+		Overtimes:          make([]models.PayslipOvertimeLine, 0, len(overtimes)),
+		Reimbursements:     make([]models.PayslipReimbursementLine, 0, len(reimbursements)),
+		ReimbursementTotal: payslip.ReimbursementTotal,
+		TakeHomePay:        payslip.TakeHomePay,
+		ExchangeRates:      payslip.ExchangeRates,
+	}
+
+	for _, overtime := range overtimes {
+		resp.Overtimes = append(resp.Overtimes, models.PayslipOvertimeLine{
+			OvertimeDate: overtime.OvertimeDate,
+			HoursWorked:  overtime.HoursWorked,
+			Description:  overtime.Description,
+			Status:       overtime.Status,
+		})
+	}
+
+	for _, reimbursement := range reimbursements {
+		resp.Reimbursements = append(resp.Reimbursements, models.PayslipReimbursementLine{
+			ID:          reimbursement.ID,
+			Description: reimbursement.Description,
+			Amount:      reimbursement.Amount,
+			Currency:    reimbursement.Currency,
+			Status:      reimbursement.Status,
+		})
+	}
+
+	return resp, nil
+}
+
+// GetSummary lists the stored payslips of a processed period together with
+// the period-wide totals.
+func (s *PayrollService) GetSummary(ctx context.Context, periodID uuid.UUID, params models.PageParams) (*models.PayrollSummaryResponse, error) {
+	period, err := s.periodRepo.GetByID(ctx, periodID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPeriodNotFound
+		}
+		return nil, err
+	}
+
+	if !period.PayrollProcessed {
+		return nil, ErrPayrollNotProcessed
+	}
+
+	if params.SortBy != "username" && params.SortBy != "amount" {
+		return nil, ErrInvalidSummarySort
+	}
+
+	summaries, err := s.payslipRepo.ListSummariesByPeriod(ctx, periodID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.payslipRepo.GetTotalsByPeriod(ctx, periodID)
+	if err != nil {
+		return nil, err
+	}
+
+	employeeCount := 0
+	for _, total := range totals {
+		employeeCount += total.EmployeeCount
+	}
+
+	return &models.PayrollSummaryResponse{
+		Period:     *period,
+		Payslips:   summaries,
+		Totals:     totals,
+		Pagination: models.NewPagination(params, employeeCount),
+	}, nil
+}
+
+func (s *PayrollService) calculatePayslip(ctx context.Context, period *models.AttendancePeriod, user *models.User, calendar *Calendar, history []models.SalaryChange, rates *rateBook) (*models.Payslip, error) {
+	workingDays := calendar.WorkingDays(period.StartDate, period.EndDate)
+	if workingDays == 0 {
+		return nil, ErrNoWorkingDays
+	}
+
+	paySalary, _ := salaryOn(history, period.EndDate)
+	currency := paySalary.Currency
+	converter := rates.converter(currency)
+
+	// salaryAt returns the salary in force on day in the pay currency, and
+	// zero before the user's first salary.
+	salaryAt := func(day time.Time) (money.Money, error) {
+		salary, ok := salaryOn(history, day)
+		if !ok {
+			return money.New(0, currency), nil
+		}
+		return converter.convert(ctx, salary)
+	}
+
+	attendances, err := s.attendanceRepo.GetByUserAndPeriod(ctx, user.ID, period.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	attended := make(map[string]bool)
+	for _, attendance := range attendances {
+		if attendance.IsPresent && calendar.IsWorkingDay(attendance.AttendanceDate) {
+			attended[attendance.AttendanceDate.Format(dateLayout)] = true
+		}
+	}
+
+	leaves, err := s.leaveRepo.GetApprovedPaidRequestsBetween(ctx, user.ID, period.StartDate, period.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Days the user both attended and had leave for only count once.
+	paidLeaveDays := 0
+	for _, leave := range leaves {
+		for day := leave.StartDate; !day.After(leave.EndDate); day = day.AddDate(0, 0, 1) {
+			key := day.Format(dateLayout)
+			if day.Before(period.StartDate) || day.After(period.EndDate) || attended[key] || !calendar.IsWorkingDay(day) {
+				continue
+			}
+			attended[key] = true
+			paidLeaveDays++
+		}
+	}
+	attendanceDays := len(attended)
+
+	overtimes, err := s.overtimeRepo.GetByUserAndPeriod(ctx, user.ID, period.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Overtime is paid at the hourly rate of the salary in force that day.
+	// Hours are weighed in hundredths so the sum stays exact.
+	var overtimeHundredths int64
+	weightedOvertime := money.New(0, currency)
+	for _, overtime := range overtimes {
+		if overtime.Status != models.OvertimeStatusApproved {
+			continue
+		}
+		hundredths := int64(math.Round(overtime.HoursWorked * 100))
+		salary, err := salaryAt(overtime.OvertimeDate)
+		if err != nil {
+			return nil, err
+		}
+		overtimeHundredths += hundredths
+		weightedOvertime = weightedOvertime.Add(salary.Mul(hundredths))
+	}
+
+	reimbursements, err := s.reimbursementRepo.GetByUserAndPeriod(ctx, user.ID, period.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reimbursements are converted one by one, each rounded on its own.
+	reimbursementTotal := money.New(0, currency)
+	var reimbursementIDs []uuid.UUID
+	for _, reimbursement := range reimbursements {
+		if reimbursement.Status != models.ReimbursementStatusApproved {
+			continue
+		}
+		amount, err := converter.convert(ctx, reimbursement.Amount)
+		if err != nil {
+			return nil, err
+		}
+		reimbursementTotal = reimbursementTotal.Add(amount)
+		reimbursementIDs = append(reimbursementIDs, reimbursement.ID)
+	}
+
+	// Every working day is worth its share of the salary in force on that
+	// day, so a raise mid-period applies from its effective date. BaseSalary
+	// is what full attendance would have paid.
+	fullSalaries, attendedSalaries := money.New(0, currency), money.New(0, currency)
+	for day := period.StartDate; !day.After(period.EndDate); day = day.AddDate(0, 0, 1) {
+		if !calendar.IsWorkingDay(day) {
+			continue
+		}
+		salary, err := salaryAt(day)
+		if err != nil {
+			return nil, err
+		}
+		fullSalaries = fullSalaries.Add(salary)
+		if attended[day.Format(dateLayout)] {
+			attendedSalaries = attendedSalaries.Add(salary)
+		}
+	}
+
+	// Rounding: amounts in another currency are converted first, each
+	// rounded to the minor unit. Each line is then computed exactly and
+	// rounded once, half away from zero, to the nearest minor unit. Take-home
+	// pay is the exact sum of the rounded lines, so a payslip always adds up.
+	days := int64(workingDays)
+	baseSalary := fullSalaries.MulDiv(1, days)
+	proratedSalary := attendedSalaries.MulDiv(1, days)
+	overtimePay := weightedOvertime.MulDiv(overtimeMultiplier, days*workingHoursPerDay*100)
+
+	return &models.Payslip{
+		UserID:             user.ID,
+		AttendancePeriodID: period.ID,
+		Currency:           currency,
+		BaseSalary:         baseSalary,
+		WorkingDays:        workingDays,
+		AttendanceDays:     attendanceDays,
+		PaidLeaveDays:      paidLeaveDays,
+		ProratedSalary:     proratedSalary,
+		OvertimeHours:      float64(overtimeHundredths) / 100,
+		OvertimePay:        overtimePay,
+		ReimbursementTotal: reimbursementTotal,
+		TakeHomePay:        proratedSalary.Add(overtimePay).Add(reimbursementTotal),
+		ExchangeRates:      converter.rates(),
+		ReimbursementIDs:   reimbursementIDs,
+	}, nil
+}
+
+// Errors
+var (
+	ErrPeriodNotFound          = NewAppError("attendance period not found", 404)
+	ErrPayrollAlreadyProcessed = NewAppError("payroll already processed for this period", 409)
+	ErrNoWorkingDays           = NewAppError("no working days in the period at the user's location", 422)
+	ErrPayrollNotProcessed     = NewAppError("payroll has not been processed for this period", 409)
+	ErrPayslipNotFound         = NewAppError("payslip not found", 404)
+	ErrInvalidSummarySort      = NewAppError("sort must be one of: username, amount", 400)
+)
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/money"
+)
+
+// The payroll fakes embed their repository interface and implement only the
+// reads calculatePayslip makes; anything else panics.
+
+type fakeAttendanceRepo struct {
+	repository.AttendanceRepository
+	attendances []models.Attendance
+}
+
+func (r fakeAttendanceRepo) GetByUserAndPeriod(context.Context, uuid.UUID, uuid.UUID) ([]models.Attendance, error) {
+	return r.attendances, nil
+}
+
+type fakeLeaveRepo struct {
+	repository.LeaveRepository
+	leaves []models.LeaveRequest
+}
+
+func (r fakeLeaveRepo) GetApprovedPaidRequestsBetween(context.Context, uuid.UUID, time.Time, time.Time) ([]models.LeaveRequest, error) {
+	return r.leaves, nil
+}
+
+type fakeOvertimeRepo struct {
+	repository.OvertimeRepository
+	overtimes []models.Overtime
+}
+
+func (r fakeOvertimeRepo) GetByUserAndPeriod(context.Context, uuid.UUID, uuid.UUID) ([]models.Overtime, error) {
+	return r.overtimes, nil
+}
+
+type fakeReimbursementRepo struct {
+	repository.ReimbursementRepository
+	reimbursements []models.Reimbursement
+}
+
+func (r fakeReimbursementRepo) GetByUserAndPeriod(context.Context, uuid.UUID, uuid.UUID) ([]models.Reimbursement, error) {
+	return r.reimbursements, nil
+}
+
+type fakeExchangeRateRepo struct {
+	repository.ExchangeRateRepository
+	rates []models.ExchangeRate
+}
+
+func (r fakeExchangeRateRepo) GetEffective(_ context.Context, from, to string, _ time.Time) (*models.ExchangeRate, error) {
+	for i := range r.rates {
+		if r.rates[i].FromCurrency == from && r.rates[i].ToCurrency == to {
+			return &r.rates[i], nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func TestCalculatePayslip(t *testing.T) {
+	on := func(month time.Month, day int) time.Time {
+		return time.Date(2025, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	idr := func(amount int64) money.Money { return money.New(amount, "IDR") }
+	usd := func(amount int64) money.Money { return money.New(amount, "USD") }
+	rate := func(s string) money.Rate {
+		r, err := money.ParseRate(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	present := func(days ...int) []models.Attendance {
+		attendances := make([]models.Attendance, len(days))
+		for i, day := range days {
+			attendances[i] = models.Attendance{AttendanceDate: on(time.June, day), IsPresent: true}
+		}
+		return attendances
+	}
+	salary := func(amount money.Money, from time.Time) models.SalaryChange {
+		return models.SalaryChange{Amount: amount, Currency: amount.Currency, EffectiveFrom: from}
+	}
+
+	// Monday 2 to Sunday 15 June 2025 has ten weekdays. Friday the 6th is a
+	// holiday, which leaves nine working days.
+	period := &models.AttendancePeriod{ID: uuid.New(), StartDate: on(time.June, 2), EndDate: on(time.June, 15)}
+	calendar := &Calendar{
+		weekend:  map[time.Weekday]bool{time.Saturday: true, time.Sunday: true},
+		holidays: map[string]models.Holiday{"2025-06-06": {HolidayDate: on(time.June, 6), Name: "Eid al-Adha"}},
+	}
+	workingDays := []int{2, 3, 4, 5, 9, 10, 11, 12, 13}
+	monthly := []models.SalaryChange{salary(idr(9_000_000_00), on(time.January, 1))}
+
+	tests := []struct {
+		name           string
+		history        []models.SalaryChange
+		attendances    []models.Attendance
+		leaves         []models.LeaveRequest
+		overtimes      []models.Overtime
+		reimbursements []models.Reimbursement
+		rates          []models.ExchangeRate
+
+		wantAttendanceDays int
+		wantPaidLeaveDays  int
+		wantBase           money.Money
+		wantProrated       money.Money
+		wantOvertimeHours  float64
+		wantOvertimePay    money.Money
+		wantReimbursements money.Money
+		wantRates          int
+	}{
+		{
+			name:               "full attendance",
+			history:            monthly,
+			attendances:        present(workingDays...),
+			wantAttendanceDays: 9,
+			wantBase:           idr(9_000_000_00),
+			wantProrated:       idr(9_000_000_00),
+		},
+		{
+			name:               "proration",
+			history:            monthly,
+			attendances:        present(2, 3, 4, 5, 9, 10),
+			wantAttendanceDays: 6,
+			wantBase:           idr(9_000_000_00),
+			wantProrated:       idr(6_000_000_00),
+		},
+		{
+			name:               "proration rounds half away from zero",
+			history:            []models.SalaryChange{salary(idr(10_000_000_00), on(time.January, 1))},
+			attendances:        present(2),
+			wantAttendanceDays: 1,
+			wantBase:           idr(10_000_000_00),
+			wantProrated:       idr(1_111_111_11),
+		},
+		{
+			name:    "holidays and weekends do not count",
+			history: monthly,
+			attendances: append(present(2, 3, 4, 5, 6, 7, 8),
+				models.Attendance{AttendanceDate: on(time.June, 9), IsPresent: false}),
+			leaves: []models.LeaveRequest{
+				// Friday the 13th to Monday the 16th: only the 13th is a
+				// working day in the period.
+				{StartDate: on(time.June, 13), EndDate: on(time.June, 16)},
+			},
+			wantAttendanceDays: 5,
+			wantPaidLeaveDays:  1,
+			wantBase:           idr(9_000_000_00),
+			wantProrated:       idr(5_000_000_00),
+		},
+		{
+			name:        "paid leave on attended days counts once",
+			history:     monthly,
+			attendances: present(2, 3, 4, 5, 9, 10),
+			leaves: []models.LeaveRequest{
+				{StartDate: on(time.June, 10), EndDate: on(time.June, 12)},
+			},
+			wantAttendanceDays: 8,
+			wantPaidLeaveDays:  2,
+			wantBase:           idr(9_000_000_00),
+			wantProrated:       idr(8_000_000_00),
+		},
+		{
+			// Four days at 9m and five at 18m.
+			name: "mid-period salary change",
+			history: []models.SalaryChange{
+				salary(idr(9_000_000_00), on(time.January, 1)),
+				salary(idr(18_000_000_00), on(time.June, 9)),
+			},
+			attendances:        present(2, 3, 9),
+			wantAttendanceDays: 3,
+			wantBase:           idr(14_000_000_00),
+			wantProrated:       idr(4_000_000_00),
+		},
+		{
+			// 9m over nine days of eight hours is 125k an hour, paid double.
+			name:        "overtime at twice the hourly rate",
+			history:     monthly,
+			attendances: present(workingDays...),
+			overtimes: []models.Overtime{
+				{OvertimeDate: on(time.June, 3), HoursWorked: 2.5, Status: models.OvertimeStatusApproved},
+				{OvertimeDate: on(time.June, 4), HoursWorked: 3, Status: models.OvertimeStatusPending},
+				{OvertimeDate: on(time.June, 5), HoursWorked: 1, Status: models.OvertimeStatusRejected},
+			},
+			wantAttendanceDays: 9,
+			wantBase:           idr(9_000_000_00),
+			wantProrated:       idr(9_000_000_00),
+			wantOvertimeHours:  2.5,
+			wantOvertimePay:    idr(625_000_00),
+		},
+		{
+			// The hour on the 10th is paid from the 18m salary: 250k doubled.
+			name: "overtime at the salary in force that day",
+			history: []models.SalaryChange{
+				salary(idr(9_000_000_00), on(time.January, 1)),
+				salary(idr(18_000_000_00), on(time.June, 9)),
+			},
+			overtimes: []models.Overtime{
+				{OvertimeDate: on(time.June, 3), HoursWorked: 1, Status: models.OvertimeStatusApproved},
+				{OvertimeDate: on(time.June, 10), HoursWorked: 1, Status: models.OvertimeStatusApproved},
+			},
+			wantBase:          idr(14_000_000_00),
+			wantProrated:      idr(0),
+			wantOvertimeHours: 2,
+			wantOvertimePay:   idr(750_000_00),
+		},
+		{
+			// 10.01 USD is 162,508.01567 IDR and 0.05 USD is 811.72835 IDR.
+			// Rounded one by one they add up to a cent more than the sum
+			// converted at once would.
+			name:    "reimbursements are converted and rounded one by one",
+			history: monthly,
+			reimbursements: []models.Reimbursement{
+				{ID: uuid.New(), Amount: usd(10_01), Status: models.ReimbursementStatusApproved},
+				{ID: uuid.New(), Amount: usd(5), Status: models.ReimbursementStatusApproved},
+				{ID: uuid.New(), Amount: idr(100_000_00), Status: models.ReimbursementStatusApproved},
+				{ID: uuid.New(), Amount: idr(999_00), Status: models.ReimbursementStatusPending},
+				{ID: uuid.New(), Amount: usd(1_00), Status: models.ReimbursementStatusPaid},
+			},
+			rates: []models.ExchangeRate{
+				{FromCurrency: "USD", ToCurrency: "IDR", Rate: rate("16234.567"), RateDate: on(time.June, 1)},
+			},
+			wantBase:           idr(9_000_000_00),
+			wantProrated:       idr(0),
+			wantReimbursements: idr(163_319_75 + 100_000_00),
+			wantRates:          1,
+		},
+		{
+			// The salary before the change to IDR is converted at the rate on
+			// the last day of the period: four days at 600 USD, five at 9m IDR.
+			name: "salary in another currency earlier in the period",
+			history: []models.SalaryChange{
+				salary(usd(600_00), on(time.January, 1)),
+				salary(idr(9_000_000_00), on(time.June, 9)),
+			},
+			attendances: present(workingDays...),
+			rates: []models.ExchangeRate{
+				{FromCurrency: "USD", ToCurrency: "IDR", Rate: rate("15000"), RateDate: on(time.June, 1)},
+			},
+			wantAttendanceDays: 9,
+			wantBase:           idr(9_000_000_00),
+			wantProrated:       idr(9_000_000_00),
+			wantRates:          1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &PayrollService{
+				attendanceRepo:    fakeAttendanceRepo{attendances: tt.attendances},
+				leaveRepo:         fakeLeaveRepo{leaves: tt.leaves},
+				overtimeRepo:      fakeOvertimeRepo{overtimes: tt.overtimes},
+				reimbursementRepo: fakeReimbursementRepo{reimbursements: tt.reimbursements},
+			}
+			rates := newRateBook(fakeExchangeRateRepo{rates: tt.rates}, period.EndDate)
+			user := &models.User{ID: uuid.New()}
+
+			got, err := s.calculatePayslip(context.Background(), period, user, calendar, tt.history, rates)
+			if err != nil {
+				t.Fatalf("calculatePayslip: %v", err)
+			}
+
+			wantOvertimePay, wantReimbursements := tt.wantOvertimePay, tt.wantReimbursements
+			if wantOvertimePay.Currency == "" {
+				wantOvertimePay = idr(0)
+			}
+			if wantReimbursements.Currency == "" {
+				wantReimbursements = idr(0)
+			}
+			wantTakeHome := tt.wantProrated.Add(wantOvertimePay).Add(wantReimbursements)
+
+			if got.Currency != "IDR" || got.WorkingDays != len(workingDays) {
+				t.Errorf("currency, working days = %s, %d, want IDR, %d", got.Currency, got.WorkingDays, len(workingDays))
+			}
+			if got.AttendanceDays != tt.wantAttendanceDays || got.PaidLeaveDays != tt.wantPaidLeaveDays {
+				t.Errorf("attendance, paid leave days = %d, %d, want %d, %d",
+					got.AttendanceDays, got.PaidLeaveDays, tt.wantAttendanceDays, tt.wantPaidLeaveDays)
+			}
+			if got.OvertimeHours != tt.wantOvertimeHours {
+				t.Errorf("overtime hours = %v, want %v", got.OvertimeHours, tt.wantOvertimeHours)
+			}
+			for _, line := range []struct {
+				name      string
+				got, want money.Money
+			}{
+				{"base salary", got.BaseSalary, tt.wantBase},
+				{"prorated salary", got.ProratedSalary, tt.wantProrated},
+				{"overtime pay", got.OvertimePay, wantOvertimePay},
+				{"reimbursements", got.ReimbursementTotal, wantReimbursements},
+				{"take-home pay", got.TakeHomePay, wantTakeHome},
+			} {
+				if line.got != line.want {
+					t.Errorf("%s = %d %s, want %d %s", line.name, line.got.Amount, line.got.Currency, line.want.Amount, line.want.Currency)
+				}
+			}
+			if len(got.ExchangeRates) != tt.wantRates {
+				t.Errorf("exchange rates = %+v, want %d", got.ExchangeRates, tt.wantRates)
+			}
+		})
+	}
+}
+
+func TestCalculatePayslipErrors(t *testing.T) {
+	on := func(day int) time.Time {
+		return time.Date(2025, time.June, day, 0, 0, 0, 0, time.UTC)
+	}
+	period := &models.AttendancePeriod{ID: uuid.New(), StartDate: on(2), EndDate: on(6)}
+	history := []models.SalaryChange{{Amount: money.New(9_000_000_00, "IDR"), Currency: "IDR", EffectiveFrom: on(1)}}
+	weekdays := &Calendar{weekend: map[time.Weekday]bool{time.Saturday: true, time.Sunday: true}}
+
+	tests := []struct {
+		name           string
+		calendar       *Calendar
+		reimbursements []models.Reimbursement
+		wantErr        string
+	}{
+		{
+			name: "no working days",
+			calendar: &Calendar{weekend: map[time.Weekday]bool{
+				time.Monday: true, time.Tuesday: true, time.Wednesday: true, time.Thursday: true, time.Friday: true,
+			}},
+			wantErr: ErrNoWorkingDays.Error(),
+		},
+		{
+			name:     "missing exchange rate",
+			calendar: weekdays,
+			reimbursements: []models.Reimbursement{
+				{Amount: money.New(10_00, "USD"), Status: models.ReimbursementStatusApproved},
+			},
+			wantErr: "no exchange rate from USD to IDR on or before 2025-06-06",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &PayrollService{
+				attendanceRepo:    fakeAttendanceRepo{},
+				leaveRepo:         fakeLeaveRepo{},
+				overtimeRepo:      fakeOvertimeRepo{},
+				reimbursementRepo: fakeReimbursementRepo{reimbursements: tt.reimbursements},
+			}
+			rates := newRateBook(fakeExchangeRateRepo{}, period.EndDate)
+
+			_, err := s.calculatePayslip(context.Background(), period, &models.User{ID: uuid.New()}, tt.calendar, history, rates)
+			var appErr *AppError
+			if !errors.As(err, &appErr) || err.Error() != tt.wantErr {
+				t.Fatalf("calculatePayslip error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
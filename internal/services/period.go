@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+)
+
+const dateLayout = "2006-01-02"
+
+// openPeriod loads the period and makes sure it still accepts submissions.
+func openPeriod(ctx context.Context, periodRepo repository.AttendancePeriodRepository, periodID uuid.UUID) (*models.AttendancePeriod, error) {
+	period, err := periodRepo.GetByID(ctx, periodID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPeriodNotFound
+		}
+		return nil, err
+	}
+
+	if err := ensurePeriodOpen(period); err != nil {
+		return nil, err
+	}
+
+	return period, nil
+}
+
+// resolvePeriod returns the open period a submission dated date belongs to.
+// When rawPeriodID is empty the period is looked up from the date instead.
+func resolvePeriod(ctx context.Context, periodRepo repository.AttendancePeriodRepository, rawPeriodID string, date, today time.Time) (*models.AttendancePeriod, error) {
+	if date.After(today) {
+		return nil, ErrFutureDate
+	}
+
+	if rawPeriodID == "" {
+		period, err := periodRepo.GetByDate(ctx, date)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, ErrNoPeriodForDate
+			}
+			return nil, err
+		}
+
+		if err := ensurePeriodOpen(period); err != nil {
+			return nil, err
+		}
+		return period, nil
+	}
+
+	periodID, err := uuid.Parse(rawPeriodID)
+	if err != nil {
+		return nil, ErrInvalidPeriodID
+	}
+
+	period, err := openPeriod(ctx, periodRepo, periodID)
+	if err != nil {
+		return nil, err
+	}
+
+	if date.Before(period.StartDate) || date.After(period.EndDate) {
+		return nil, ErrDateOutsidePeriod
+	}
+
+	return period, nil
+}
+
+func ensurePeriodOpen(period *models.AttendancePeriod) error {
+	if period.PayrollProcessed {
+		return ErrPeriodProcessed
+	}
+	if !period.IsActive {
+		return ErrPeriodInactive
+	}
+	return nil
+}
+
+// mapWriteError surfaces the database-level period guard as a 409.
+func mapWriteError(err error) error {
+	if errors.Is(err, repository.ErrPeriodLocked) {
+		return ErrPeriodLocked
+	}
+	return err
+}
+
+// Errors
+var (
+	ErrPeriodProcessed   = NewAppError("attendance period payroll has been processed; changes are no longer allowed", 409)
+	ErrPeriodInactive    = NewAppError("attendance period is inactive", 409)
+	ErrPeriodLocked      = NewAppError("attendance period is closed for changes", 409)
+	ErrFutureDate        = NewAppError("date cannot be in the future", 400)
+	ErrDateOutsidePeriod = NewAppError("date is outside the attendance period", 400)
+	ErrNoPeriodForDate   = NewAppError("no attendance period covers this date", 404)
+)
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+	"github.com/jordanhimawan/payroll-mgmt/internal/storage"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/money"
+)
+
+// MaxReceiptSize is the largest receipt file accepted, in bytes.
+const MaxReceiptSize = 5 << 20
+
+// receiptExtensions maps the accepted receipt content types, as sniffed from
+// the file contents, to the extension used in storage.
+var receiptExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+// reimbursementTransitions lists the statuses each status may move to.
+// Approved reimbursements are moved to paid by the payroll run.
+var reimbursementTransitions = map[string][]string{
+	models.ReimbursementStatusPending:  {models.ReimbursementStatusApproved, models.ReimbursementStatusRejected},
+	models.ReimbursementStatusApproved: {models.ReimbursementStatusPaid},
+}
+
+func canTransitionReimbursement(from, to string) bool {
+	for _, next := range reimbursementTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+type ReimbursementService struct {
+	reimbursementRepo repository.ReimbursementRepository
+	periodRepo        repository.AttendancePeriodRepository
+	receiptStorage    storage.Storage
+}
+
+func NewReimbursementService(
+	reimbursementRepo repository.ReimbursementRepository,
+	periodRepo repository.AttendancePeriodRepository,
+	receiptStorage storage.Storage,
+) *ReimbursementService {
+	return &ReimbursementService{
+		reimbursementRepo: reimbursementRepo,
+		periodRepo:        periodRepo,
+		receiptStorage:    receiptStorage,
+	}
+}
+
+func (s *ReimbursementService) SubmitReimbursement(ctx context.Context, userID uuid.UUID, req models.SubmitReimbursementRequest, ipAddress string) (*models.Reimbursement, error) {
+	if !req.Amount.IsPositive() {
+		return nil, ErrInvalidReimbursementAmount
+	}
+
+	if req.Description == "" {
+		return nil, ErrReimbursementDescriptionRequired
+	}
+
+	currency, err := currencyOrDefault(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	periodID, err := uuid.Parse(req.AttendancePeriodID)
+	if err != nil {
+		return nil, ErrInvalidPeriodID
+	}
+
+	if _, err := openPeriod(ctx, s.periodRepo, periodID); err != nil {
+		return nil, err
+	}
+
+	reimbursement := &models.Reimbursement{
+		UserID:             userID,
+		AttendancePeriodID: periodID,
+		Amount:             money.New(req.Amount.Amount, currency),
+		Currency:           currency,
+		Description:        req.Description,
+		Status:             models.ReimbursementStatusPending,
+		IPAddress:          ipAddress,
+		CreatedBy:          userID,
+	}
+
+	if err := s.reimbursementRepo.Create(ctx, reimbursement); err != nil {
+		return nil, mapWriteError(err)
+	}
+
+	return reimbursement, nil
+}
+
+// UpdateReimbursement lets an employee correct their own reimbursement while
+// it is still pending review.
+func (s *ReimbursementService) UpdateReimbursement(ctx context.Context, userID, reimbursementID uuid.UUID, req models.UpdateReimbursementRequest) (*models.Reimbursement, error) {
+	if !req.Amount.IsPositive() {
+		return nil, ErrInvalidReimbursementAmount
+	}
+
+	if req.Description == "" {
+		return nil, ErrReimbursementDescriptionRequired
+	}
+
+	currency, err := currencyOrDefault(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	reimbursement, err := s.getReimbursement(ctx, reimbursementID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Other users' reimbursements are reported as missing rather than forbidden.
+	if reimbursement.UserID != userID {
+		return nil, ErrReimbursementNotFound
+	}
+
+	if reimbursement.Status != models.ReimbursementStatusPending {
+		return nil, ErrReimbursementAlreadyReviewed
+	}
+
+	if _, err := openPeriod(ctx, s.periodRepo, reimbursement.AttendancePeriodID); err != nil {
+		return nil, err
+	}
+
+	reimbursement.Amount = money.New(req.Amount.Amount, currency)
+	reimbursement.Currency = currency
+	reimbursement.Description = req.Description
+	reimbursement.UpdatedBy = &userID
+
+	if err := s.reimbursementRepo.Update(ctx, reimbursement, models.ReimbursementStatusPending); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, ErrReimbursementAlreadyReviewed
+		}
+		return nil, mapWriteError(err)
+	}
+
+	return reimbursement, nil
+}
+
+// UploadReceipt stores a receipt file for the user's own pending
+// reimbursement, replacing any earlier one. The same file cannot back two
+// reimbursements unless the other one was rejected.
+func (s *ReimbursementService) UploadReceipt(ctx context.Context, userID, reimbursementID uuid.UUID, fileName string, content []byte) (*models.Reimbursement, error) {
+	if len(content) == 0 {
+		return nil, ErrReceiptEmpty
+	}
+	if len(content) > MaxReceiptSize {
+		return nil, ErrReceiptTooLarge
+	}
+
+	contentType := http.DetectContentType(content)
+	ext, ok := receiptExtensions[contentType]
+	if !ok {
+		return nil, ErrReceiptType
+	}
+
+	reimbursement, err := s.getReimbursement(ctx, reimbursementID)
+	if err != nil {
+		return nil, err
+	}
+
+	if reimbursement.UserID != userID {
+		return nil, ErrReimbursementNotFound
+	}
+
+	if reimbursement.Status != models.ReimbursementStatusPending {
+		return nil, ErrReimbursementAlreadyReviewed
+	}
+
+	if _, err := openPeriod(ctx, s.periodRepo, reimbursement.AttendancePeriodID); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	duplicate, err := s.reimbursementRepo.GetActiveByReceiptHash(ctx, hash)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if duplicate != nil && duplicate.ID != reimbursement.ID {
+		return nil, ErrDuplicateReceipt
+	}
+
+	key := fmt.Sprintf("receipts/%s/%s%s", reimbursement.ID, hash, ext)
+	if err := s.receiptStorage.Put(ctx, key, bytes.NewReader(content)); err != nil {
+		return nil, err
+	}
+
+	previousKey := reimbursement.ReceiptKey
+	reimbursement.ReceiptKey = key
+	reimbursement.ReceiptFileName = filepath.Base(fileName)
+	reimbursement.ReceiptContentType = contentType
+	reimbursement.ReceiptSize = int64(len(content))
+	reimbursement.ReceiptSHA256 = hash
+	reimbursement.ReceiptURL = fmt.Sprintf("/api/v1/reimbursements/%s/receipt", reimbursement.ID)
+	reimbursement.UpdatedBy = &userID
+
+	if err := s.reimbursementRepo.Update(ctx, reimbursement, models.ReimbursementStatusPending); err != nil {
+		if key != previousKey {
+			s.receiptStorage.Delete(ctx, key)
+		}
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrDuplicateReceipt
+		}
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, ErrReimbursementAlreadyReviewed
+		}
+		return nil, mapWriteError(err)
+	}
+
+	if previousKey != "" && previousKey != key {
+		s.receiptStorage.Delete(ctx, previousKey)
+	}
+
+	return reimbursement, nil
+}
+
+// OpenReceipt returns the receipt of a reimbursement for download. Users can
+// fetch their own receipts; canReadAll grants access to everyone's.
+func (s *ReimbursementService) OpenReceipt(ctx context.Context, userID, reimbursementID uuid.UUID, canReadAll bool) (*models.Reimbursement, io.ReadCloser, error) {
+	reimbursement, err := s.getReimbursement(ctx, reimbursementID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if reimbursement.UserID != userID && !canReadAll {
+		return nil, nil, ErrReimbursementNotFound
+	}
+
+	if reimbursement.ReceiptKey == "" {
+		return nil, nil, ErrReceiptNotFound
+	}
+
+	file, err := s.receiptStorage.Open(ctx, reimbursement.ReceiptKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, ErrReceiptNotFound
+		}
+		return nil, nil, err
+	}
+
+	return reimbursement, file, nil
+}
+
+// ListReimbursements lists reimbursements across all users.
+func (s *ReimbursementService) ListReimbursements(ctx context.Context, filter models.ReimbursementFilter, params models.PageParams) (*models.ReimbursementListResponse, error) {
+	if filter.Status != "" && !isReimbursementStatus(filter.Status) {
+		return nil, ErrInvalidReimbursementStatus
+	}
+
+	if params.SortBy != "created_at" && params.SortBy != "amount" {
+		return nil, ErrInvalidReimbursementSort
+	}
+
+	reimbursements, total, err := s.reimbursementRepo.List(ctx, filter, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ReimbursementListResponse{
+		Reimbursements: reimbursements,
+		Pagination:     models.NewPagination(params, total),
+	}, nil
+}
+
+// Review approves or rejects a pending reimbursement. Reviewers cannot review
+// their own claims, and rejections need a reason.
+func (s *ReimbursementService) Review(ctx context.Context, reviewerID, reimbursementID uuid.UUID, approve bool, reason string) (*models.Reimbursement, error) {
+	if !approve && reason == "" {
+		return nil, ErrRejectionReasonRequired
+	}
+
+	reimbursement, err := s.getReimbursement(ctx, reimbursementID)
+	if err != nil {
+		return nil, err
+	}
+
+	if reimbursement.UserID == reviewerID {
+		return nil, ErrSelfReview
+	}
+
+	status := models.ReimbursementStatusRejected
+	if approve {
+		status = models.ReimbursementStatusApproved
+	}
+
+	if !canTransitionReimbursement(reimbursement.Status, status) {
+		return nil, NewAppError("cannot move reimbursement from "+reimbursement.Status+" to "+status, 409)
+	}
+
+	if _, err := openPeriod(ctx, s.periodRepo, reimbursement.AttendancePeriodID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	fromStatus := reimbursement.Status
+	reimbursement.Status = status
+	reimbursement.ReviewedBy = &reviewerID
+	reimbursement.ReviewedAt = &now
+	reimbursement.ReviewReason = reason
+	reimbursement.UpdatedBy = &reviewerID
+
+	if err := s.reimbursementRepo.Update(ctx, reimbursement, fromStatus); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, ErrReimbursementChanged
+		}
+		return nil, mapWriteError(err)
+	}
+
+	return reimbursement, nil
+}
+
+func (s *ReimbursementService) getReimbursement(ctx context.Context, id uuid.UUID) (*models.Reimbursement, error) {
+	reimbursement, err := s.reimbursementRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrReimbursementNotFound
+		}
+		return nil, err
+	}
+	return reimbursement, nil
+}
+
+func isReimbursementStatus(status string) bool {
+	switch status {
+	case models.ReimbursementStatusPending, models.ReimbursementStatusApproved,
+		models.ReimbursementStatusRejected, models.ReimbursementStatusPaid:
+		return true
+	}
+	return false
+}
+
+// Errors
+var (
+	ErrReimbursementNotFound            = NewAppError("reimbursement not found", 404)
+	ErrReimbursementAlreadyReviewed     = NewAppError("reimbursement has already been reviewed and can no longer be edited", 409)
+	ErrReimbursementChanged             = NewAppError("reimbursement was changed while you were reviewing it; reload and try again", 409)
+	ErrInvalidReimbursementStatus       = NewAppError("status must be one of: pending, approved, rejected, paid", 400)
+	ErrInvalidReimbursementSort         = NewAppError("sort must be one of: created_at, amount", 400)
+	ErrSelfReview                       = NewAppError("you cannot review your own submission", 403)
+	ErrReceiptEmpty                     = NewAppError("receipt file is empty", 400)
+	ErrReceiptTooLarge                  = NewAppError("receipt file must be 5 MB or smaller", 413)
+	ErrReceiptType                      = NewAppError("receipt must be a PDF, JPEG or PNG file", 415)
+	ErrDuplicateReceipt                 = NewAppError("this receipt has already been submitted", 409)
+	ErrReceiptNotFound                  = NewAppError("receipt not found", 404)
+	ErrInvalidReimbursementAmount       = NewAppError("amount must be positive", 400)
+	ErrReimbursementDescriptionRequired = NewAppError("description is required", 400)
+)
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/money"
+)
+
+// endOfTime is the upper bound used to find every period from a date onwards.
+var endOfTime = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+type SalaryService struct {
+	salaryRepo repository.SalaryRepository
+	userRepo   repository.UserRepository
+	roleRepo   repository.RoleRepository
+	periodRepo repository.AttendancePeriodRepository
+}
+
+func NewSalaryService(
+	salaryRepo repository.SalaryRepository,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	periodRepo repository.AttendancePeriodRepository,
+) *SalaryService {
+	return &SalaryService{
+		salaryRepo: salaryRepo,
+		userRepo:   userRepo,
+		roleRepo:   roleRepo,
+		periodRepo: periodRepo,
+	}
+}
+
+// GetHistory lists a user's past, current and scheduled salaries, oldest
+// first.
+func (s *SalaryService) GetHistory(ctx context.Context, userID uuid.UUID) ([]models.SalaryChange, error) {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.salaryRepo.GetByUser(ctx, userID)
+}
+
+// ScheduleChange sets a new salary from a date, which may lie in the future.
+// The date cannot fall on or before a period whose payroll has been
+// processed, so processed payslips can always be reproduced. Nobody can change
+// their own salary.
+func (s *SalaryService) ScheduleChange(ctx context.Context, userID uuid.UUID, req models.ScheduleSalaryChangeRequest, createdBy uuid.UUID) (*models.SalaryChange, error) {
+	if !req.Amount.IsPositive() {
+		return nil, ErrInvalidSalary
+	}
+
+	currency, err := currencyOrDefault(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	effectiveFrom, err := time.Parse(dateLayout, req.EffectiveFrom)
+	if err != nil {
+		return nil, ErrInvalidEffectiveDate
+	}
+
+	if err := s.ensureCanManage(ctx, userID, createdBy); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureUnprocessedFrom(ctx, effectiveFrom); err != nil {
+		return nil, err
+	}
+
+	change := &models.SalaryChange{
+		UserID:        userID,
+		Amount:        money.New(req.Amount.Amount, currency),
+		Currency:      currency,
+		EffectiveFrom: effectiveFrom,
+		Reason:        strings.TrimSpace(req.Reason),
+		CreatedBy:     &createdBy,
+	}
+
+	if err := s.salaryRepo.Create(ctx, change); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrSalaryChangeExists
+		}
+		// The database refuses changes that would alter a payslip stored
+		// since the check above.
+		if errors.Is(err, repository.ErrPeriodLocked) {
+			return nil, ErrSalaryChangeInProcessedPeriod
+		}
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// CancelChange removes a salary change that no processed payroll relied on.
+func (s *SalaryService) CancelChange(ctx context.Context, userID, changeID, cancelledBy uuid.UUID) error {
+	if err := s.ensureCanManage(ctx, userID, cancelledBy); err != nil {
+		return err
+	}
+
+	change, err := s.salaryRepo.GetByID(ctx, changeID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSalaryChangeNotFound
+		}
+		return err
+	}
+
+	if change.UserID != userID {
+		return ErrSalaryChangeNotFound
+	}
+
+	if err := s.ensureUnprocessedFrom(ctx, change.EffectiveFrom); err != nil {
+		return err
+	}
+
+	if err := s.salaryRepo.Delete(ctx, changeID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSalaryChangeNotFound
+		}
+		if errors.Is(err, repository.ErrPeriodLocked) {
+			return ErrSalaryChangeInProcessedPeriod
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ensureUnprocessedFrom rejects salary changes that would alter a processed
+// period, i.e. one that ends on or after date.
+func (s *SalaryService) ensureUnprocessedFrom(ctx context.Context, date time.Time) error {
+	periods, err := s.periodRepo.GetOverlapping(ctx, date, endOfTime)
+	if err != nil {
+		return err
+	}
+
+	for _, period := range periods {
+		if period.PayrollProcessed {
+			return ErrSalaryChangeInProcessedPeriod
+		}
+	}
+	return nil
+}
+
+// ensureCanManage checks that actorID may change userID's salary: it is not
+// their own, and their role grants everything the user's role does.
+func (s *SalaryService) ensureCanManage(ctx context.Context, userID, actorID uuid.UUID) error {
+	if userID == actorID {
+		return ErrCannotChangeOwnSalary
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return ensureCanManageRoles(ctx, s.userRepo, s.roleRepo, actorID, user.Role)
+}
+
+func (s *SalaryService) getUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByIDIncludingInactive(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// salaryOn returns the salary in force on date from a history sorted oldest
+// first, and false if none had taken effect yet.
+func salaryOn(history []models.SalaryChange, date time.Time) (money.Money, bool) {
+	var amount money.Money
+	found := false
+	for _, change := range history {
+		if change.EffectiveFrom.After(date) {
+			break
+		}
+		amount, found = change.Amount, true
+	}
+	return amount, found
+}
+
+// Errors
+var (
+	ErrSalaryChangeNotFound          = NewAppError("salary change not found", 404)
+	ErrSalaryChangeExists            = NewAppError("a salary change already takes effect on this date", 409)
+	ErrCannotChangeOwnSalary         = NewAppError("you cannot change your own salary", 403)
+	ErrInvalidEffectiveDate          = NewAppError("invalid effective date format", 400)
+	ErrSalaryChangeInProcessedPeriod = NewAppError("salary changes cannot take effect on or before a processed payroll period", 409)
+)
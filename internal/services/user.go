@@ -0,0 +1,449 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jordanhimawan/payroll-mgmt/internal/models"
+	"github.com/jordanhimawan/payroll-mgmt/internal/repository"
+	"github.com/jordanhimawan/payroll-mgmt/pkg/money"
+)
+
+const (
+	// maxManagerDepth bounds the walk up the reporting line when checking for
+	// cycles.
+	maxManagerDepth = 100
+)
+
+type UserService struct {
+	userRepo        repository.UserRepository
+	roleRepo        repository.RoleRepository
+	calendarRepo    repository.CalendarRepository
+	calendarService *CalendarService
+	tokenRepo       repository.TokenRepository
+	loginRepo       repository.LoginRepository
+	mfaRepo         repository.MFARepository
+	passwordRepo    repository.PasswordRepository
+	passwordPolicy  *PasswordPolicy
+}
+
+func NewUserService(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	calendarRepo repository.CalendarRepository,
+	calendarService *CalendarService,
+	tokenRepo repository.TokenRepository,
+	loginRepo repository.LoginRepository,
+	mfaRepo repository.MFARepository,
+	passwordRepo repository.PasswordRepository,
+	passwordPolicy *PasswordPolicy,
+) *UserService {
+	return &UserService{
+		userRepo:        userRepo,
+		roleRepo:        roleRepo,
+		calendarRepo:    calendarRepo,
+		calendarService: calendarService,
+		tokenRepo:       tokenRepo,
+		loginRepo:       loginRepo,
+		mfaRepo:         mfaRepo,
+		passwordRepo:    passwordRepo,
+		passwordPolicy:  passwordPolicy,
+	}
+}
+
+// CreateUser adds a user whose password, chosen by the admin, must be
+// changed at their first login.
+func (s *UserService) CreateUser(ctx context.Context, req models.CreateUserRequest, createdBy uuid.UUID) (*models.User, error) {
+	if err := s.ensureCanManageRoles(ctx, createdBy, req.Role); err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		ID:                 uuid.New(),
+		IsActive:           true,
+		MustChangePassword: true,
+		CreatedBy:          &createdBy,
+	}
+	err := s.applyUserFields(ctx, user, req.Username, req.Role, req.ManagerID, req.LocationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Salary != nil {
+		if !req.Salary.IsPositive() {
+			return nil, ErrInvalidSalary
+		}
+		currency, err := currencyOrDefault(req.SalaryCurrency)
+		if err != nil {
+			return nil, err
+		}
+		salary := money.New(req.Salary.Amount, currency)
+		user.Salary = &salary
+		user.SalaryCurrency = currency
+	}
+
+	// A new user has no password history yet, so the policy is given one
+	// without an ID to skip that check.
+	passwordHash, err := s.passwordPolicy.Hash(ctx, &models.User{Username: user.Username}, req.Password)
+	if err != nil {
+		return nil, err
+	}
+	user.PasswordHash = passwordHash
+
+	if err := s.userRepo.Create(ctx, user, s.calendarService.Today()); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrUsernameTaken
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *UserService) ListUsers(ctx context.Context, filter models.UserFilter, params models.PageParams) (*models.UserListResponse, error) {
+	if _, ok := userSortKeys[params.SortBy]; !ok {
+		return nil, ErrInvalidUserSort
+	}
+
+	users, total, err := s.userRepo.List(ctx, filter, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserListResponse{
+		Users:      users,
+		Pagination: models.NewPagination(params, total),
+	}, nil
+}
+
+func (s *UserService) GetUser(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByIDIncludingInactive(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// UpdateUser edits a user's profile, role and reporting line. Admins
+// cannot change their own role, so there is always someone left who can.
+func (s *UserService) UpdateUser(ctx context.Context, id uuid.UUID, req models.UpdateUserRequest, updatedBy uuid.UUID) (*models.User, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if id == updatedBy && req.Role != user.Role {
+		return nil, ErrCannotChangeOwnRole
+	}
+
+	if err := s.ensureCanManageRoles(ctx, updatedBy, user.Role, req.Role); err != nil {
+		return nil, err
+	}
+
+	err = s.applyUserFields(ctx, user, req.Username, req.Role, req.ManagerID, req.LocationID)
+	if err != nil {
+		return nil, err
+	}
+	user.UpdatedBy = &updatedBy
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrUsernameTaken
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// SetPassword replaces a user's password on their behalf. The user must
+// change it at their next login, and their sessions are logged out.
+func (s *UserService) SetPassword(ctx context.Context, id uuid.UUID, password string, updatedBy uuid.UUID) error {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ensureCanManageRoles(ctx, updatedBy, user.Role); err != nil {
+		return err
+	}
+
+	passwordHash, err := s.passwordPolicy.Hash(ctx, user, password)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, id, passwordHash, true, updatedBy); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	return s.tokenRepo.RevokeUser(ctx, id)
+}
+
+// IssuePasswordReset returns a one-time token the user can set a new
+// password with, voiding earlier ones. The admin passes it on.
+func (s *UserService) IssuePasswordReset(ctx context.Context, id, createdBy uuid.UUID) (*models.PasswordResetResponse, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureCanManageRoles(ctx, createdBy, user.Role); err != nil {
+		return nil, err
+	}
+
+	return issueResetToken(ctx, s.passwordRepo, id, &createdBy, passwordResetTTL)
+}
+
+// Deactivate blocks a user from logging in, logs out all their sessions and
+// drops them from future payroll runs. Their history is kept.
+func (s *UserService) Deactivate(ctx context.Context, id, updatedBy uuid.UUID) (*models.User, error) {
+	if id == updatedBy {
+		return nil, ErrCannotDeactivateSelf
+	}
+	return s.setActive(ctx, id, false, updatedBy)
+}
+
+func (s *UserService) Reactivate(ctx context.Context, id, updatedBy uuid.UUID) (*models.User, error) {
+	return s.setActive(ctx, id, true, updatedBy)
+}
+
+func (s *UserService) setActive(ctx context.Context, id uuid.UUID, active bool, updatedBy uuid.UUID) (*models.User, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureCanManageRoles(ctx, updatedBy, user.Role); err != nil {
+		return nil, err
+	}
+
+	if user.IsActive == active {
+		if active {
+			return nil, ErrUserAlreadyActive
+		}
+		return nil, ErrUserAlreadyInactive
+	}
+
+	user.IsActive = active
+	user.UpdatedBy = &updatedBy
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if !active {
+		if err := s.tokenRepo.RevokeUser(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// Unlock lifts a login lockout on the user's username and clears its failed
+// attempts. Lockouts on client IPs expire on their own.
+func (s *UserService) Unlock(ctx context.Context, id, updatedBy uuid.UUID) error {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ensureCanManageRoles(ctx, updatedBy, user.Role); err != nil {
+		return err
+	}
+
+	return s.loginRepo.Reset(ctx, models.LoginScopeUsername, strings.ToLower(user.Username))
+}
+
+// ResetMFA turns off two-factor authentication for a user who lost their
+// authenticator and recovery codes, and logs out their sessions. Users whose
+// role requires MFA enrol again at their next login. Users turn off their own
+// MFA with a code instead.
+func (s *UserService) ResetMFA(ctx context.Context, id, updatedBy uuid.UUID) error {
+	if id == updatedBy {
+		return ErrCannotResetOwnMFA
+	}
+
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ensureCanManageRoles(ctx, updatedBy, user.Role); err != nil {
+		return err
+	}
+
+	if err := s.mfaRepo.Disable(ctx, id); err != nil {
+		return err
+	}
+	return s.tokenRepo.RevokeUser(ctx, id)
+}
+
+// GetLoginHistory lists the user's login attempts, newest first.
+func (s *UserService) GetLoginHistory(ctx context.Context, id uuid.UUID, params models.PageParams) (*models.LoginHistoryResponse, error) {
+	if _, err := s.GetUser(ctx, id); err != nil {
+		return nil, err
+	}
+
+	attempts, total, err := s.loginRepo.ListHistory(ctx, id, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginHistoryResponse{
+		Attempts:   attempts,
+		Pagination: models.NewPagination(params, total),
+	}, nil
+}
+
+// ensureCanManageRoles lets actorID manage users holding roles only if the
+// actor's own role grants every permission those roles do, so managing
+// accounts can never be used to gain a permission.
+func (s *UserService) ensureCanManageRoles(ctx context.Context, actorID uuid.UUID, roles ...string) error {
+	return ensureCanManageRoles(ctx, s.userRepo, s.roleRepo, actorID, roles...)
+}
+
+func ensureCanManageRoles(ctx context.Context, userRepo repository.UserRepository, roleRepo repository.RoleRepository, actorID uuid.UUID, roles ...string) error {
+	actor, err := userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrRolePermissionsRequired
+		}
+		return err
+	}
+
+	held, err := roleRepo.GetPermissions(ctx, actor.Role)
+	if err != nil {
+		return err
+	}
+	granted := make(map[string]struct{}, len(held))
+	for _, permission := range held {
+		granted[permission] = struct{}{}
+	}
+
+	for _, role := range roles {
+		if role == actor.Role {
+			continue
+		}
+		permissions, err := roleRepo.GetPermissions(ctx, role)
+		if err != nil {
+			return err
+		}
+		for _, permission := range permissions {
+			if _, ok := granted[permission]; !ok {
+				return ErrRolePermissionsRequired
+			}
+		}
+	}
+	return nil
+}
+
+// applyUserFields validates the editable fields and copies them onto user.
+func (s *UserService) applyUserFields(ctx context.Context, user *models.User, username, role, rawManagerID, rawLocationID string) error {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return ErrUsernameRequired
+	}
+
+	exists, err := s.roleRepo.Exists(ctx, role)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrInvalidRole
+	}
+
+	var managerID *uuid.UUID
+	if rawManagerID != "" {
+		id, err := uuid.Parse(rawManagerID)
+		if err != nil {
+			return ErrManagerNotFound
+		}
+		if err := s.ensureValidManager(ctx, user.ID, id); err != nil {
+			return err
+		}
+		managerID = &id
+	}
+
+	var locationID *uuid.UUID
+	if rawLocationID != "" {
+		id, err := uuid.Parse(rawLocationID)
+		if err != nil {
+			return ErrLocationNotFound
+		}
+		if _, err := s.calendarRepo.GetLocation(ctx, id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrLocationNotFound
+			}
+			return err
+		}
+		locationID = &id
+	}
+
+	user.Username = username
+	user.Role = role
+	user.ManagerID = managerID
+	user.LocationID = locationID
+	return nil
+}
+
+// ensureValidManager checks that managerID is an active user and that making
+// them userID's manager does not close a loop in the reporting line.
+func (s *UserService) ensureValidManager(ctx context.Context, userID, managerID uuid.UUID) error {
+	manager, err := s.userRepo.GetByID(ctx, managerID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrManagerNotFound
+		}
+		return err
+	}
+
+	for depth := 0; depth < maxManagerDepth; depth++ {
+		if manager.ID == userID {
+			return ErrManagerCycle
+		}
+		if manager.ManagerID == nil {
+			return nil
+		}
+
+		manager, err = s.userRepo.GetByIDIncludingInactive(ctx, *manager.ManagerID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return ErrManagerCycle
+}
+
+// userSortKeys are the sort keys a user listing accepts.
+var userSortKeys = map[string]struct{}{
+	"username":   {},
+	"created_at": {},
+	"salary":     {},
+}
+
+// Errors
+var (
+	ErrUserNotFound            = NewAppError("user not found", 404)
+	ErrUsernameRequired        = NewAppError("username is required", 400)
+	ErrUsernameTaken           = NewAppError("username is already taken", 409)
+	ErrInvalidRole             = NewAppError("role does not exist", 400)
+	ErrInvalidSalary           = NewAppError("salary must be greater than 0", 400)
+	ErrManagerNotFound         = NewAppError("manager not found or inactive", 400)
+	ErrManagerCycle            = NewAppError("a user cannot report to themselves or to someone who reports to them", 400)
+	ErrCannotChangeOwnRole     = NewAppError("you cannot change your own role", 409)
+	ErrRolePermissionsRequired = NewAppError("you cannot manage users whose role has permissions yours lacks", 403)
+	ErrCannotResetOwnMFA       = NewAppError("you cannot reset your own MFA; disable it with a code instead", 409)
+	ErrCannotDeactivateSelf    = NewAppError("you cannot deactivate your own account", 409)
+	ErrUserAlreadyActive       = NewAppError("user is already active", 409)
+	ErrUserAlreadyInactive     = NewAppError("user is already inactive", 409)
+	ErrInvalidUserSort         = NewAppError("sort must be one of: username, created_at, salary", 400)
+)
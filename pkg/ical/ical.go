@@ -0,0 +1,124 @@
+// Package ical reads the all-day events of an iCalendar (RFC 5545) file,
+// which is the format public holiday calendars are published in.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type Event struct {
+	Summary string
+	Start   time.Time // first day, at midnight UTC
+	End     time.Time // day after the last day, at midnight UTC
+}
+
+// Days returns every date the event covers.
+func (e Event) Days() []time.Time {
+	var days []time.Time
+	for day := e.Start; day.Before(e.End); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// ParseEvents returns the VEVENTs in r. Times are truncated to their date, and
+// events without DTEND last a single day.
+func ParseEvents(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var current *Event
+	for i, line := range lines {
+		name, params, value, ok := splitProperty(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			current = &Event{}
+
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN", i+1)
+			}
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: event %q has no DTSTART", i+1, current.Summary)
+			}
+			if current.End.IsZero() || !current.End.After(current.Start) {
+				current.End = current.Start.AddDate(0, 0, 1)
+			}
+			events = append(events, *current)
+			current = nil
+
+		case current == nil:
+			continue
+
+		case name == "SUMMARY":
+			current.Summary = unescape(value)
+
+		case name == "DTSTART", name == "DTEND":
+			date, err := parseDate(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			if name == "DTSTART" {
+				current.Start = date
+			} else {
+				current.End = date
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// unfold joins continuation lines, which start with a space or tab.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// splitProperty splits "NAME;PARAM=X:VALUE" into its parts.
+func splitProperty(line string) (name, params, value string, ok bool) {
+	head, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", "", "", false
+	}
+	name, params, _ = strings.Cut(head, ";")
+	return strings.ToUpper(name), params, value, true
+}
+
+func parseDate(value, params string) (time.Time, error) {
+	if strings.Contains(strings.ToUpper(params), "VALUE=DATE") && !strings.Contains(value, "T") {
+		return time.Parse("20060102", value)
+	}
+
+	// Date-times only contribute their calendar date.
+	if len(value) >= 8 {
+		if date, err := time.Parse("20060102", value[:8]); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+func unescape(value string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(value)
+}
@@ -0,0 +1,146 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Event
+		wantErr string
+	}{
+		{
+			name: "all-day events",
+			input: "BEGIN:VCALENDAR\r\n" +
+				"BEGIN:VEVENT\r\n" +
+				"DTSTART;VALUE=DATE:20250101\r\n" +
+				"DTEND;VALUE=DATE:20250102\r\n" +
+				"SUMMARY:New Year's Day\r\n" +
+				"END:VEVENT\r\n" +
+				"BEGIN:VEVENT\r\n" +
+				"DTSTART;VALUE=DATE:20250331\r\n" +
+				"DTEND;VALUE=DATE:20250402\r\n" +
+				"SUMMARY:Eid al-Fitr\r\n" +
+				"END:VEVENT\r\n" +
+				"END:VCALENDAR\r\n",
+			want: []Event{
+				{Summary: "New Year's Day", Start: date(2025, 1, 1), End: date(2025, 1, 2)},
+				{Summary: "Eid al-Fitr", Start: date(2025, 3, 31), End: date(2025, 4, 2)},
+			},
+		},
+		{
+			name: "missing DTEND lasts one day",
+			input: "BEGIN:VEVENT\n" +
+				"DTSTART;VALUE=DATE:20250817\n" +
+				"SUMMARY:Independence Day\n" +
+				"END:VEVENT\n",
+			want: []Event{
+				{Summary: "Independence Day", Start: date(2025, 8, 17), End: date(2025, 8, 18)},
+			},
+		},
+		{
+			name: "DTEND not after DTSTART lasts one day",
+			input: "BEGIN:VEVENT\n" +
+				"DTSTART;VALUE=DATE:20251225\n" +
+				"DTEND;VALUE=DATE:20251225\n" +
+				"SUMMARY:Christmas Day\n" +
+				"END:VEVENT\n",
+			want: []Event{
+				{Summary: "Christmas Day", Start: date(2025, 12, 25), End: date(2025, 12, 26)},
+			},
+		},
+		{
+			name: "date-times keep their date",
+			input: "BEGIN:VEVENT\n" +
+				"DTSTART:20250501T090000Z\n" +
+				"DTEND:20250501T170000Z\n" +
+				"SUMMARY:Labour Day\n" +
+				"END:VEVENT\n",
+			want: []Event{
+				{Summary: "Labour Day", Start: date(2025, 5, 1), End: date(2025, 5, 2)},
+			},
+		},
+		{
+			name: "folded and escaped summary",
+			input: "BEGIN:VEVENT\n" +
+				"DTSTART;VALUE=DATE:20250529\n" +
+				"SUMMARY:Ascension Day\\, \n" +
+				" observed\\; office\\nclosed\n" +
+				"END:VEVENT\n",
+			want: []Event{
+				{Summary: "Ascension Day, observed; office closed", Start: date(2025, 5, 29), End: date(2025, 5, 30)},
+			},
+		},
+		{
+			name: "properties outside events are ignored",
+			input: "BEGIN:VCALENDAR\n" +
+				"SUMMARY:Calendar\n" +
+				"DTSTART:not a date\n" +
+				"X-WR-CALNAME:Holidays\n" +
+				"END:VCALENDAR\n",
+			want: nil,
+		},
+		{
+			name:    "event without DTSTART",
+			input:   "BEGIN:VEVENT\nSUMMARY:Undated\nEND:VEVENT\n",
+			wantErr: `line 3: event "Undated" has no DTSTART`,
+		},
+		{
+			name:    "END without BEGIN",
+			input:   "END:VEVENT\n",
+			wantErr: "line 1: END:VEVENT without BEGIN",
+		},
+		{
+			name:    "invalid date",
+			input:   "BEGIN:VEVENT\nDTSTART;VALUE=DATE:2025-01-01\nEND:VEVENT\n",
+			wantErr: "line 2:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEvents(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseEvents error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseEvents: %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseEvents returned %d events, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if got[i].Summary != tt.want[i].Summary || !got[i].Start.Equal(tt.want[i].Start) || !got[i].End.Equal(tt.want[i].End) {
+					t.Errorf("event %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEventDays(t *testing.T) {
+	event := Event{Start: date(2025, 12, 30), End: date(2026, 1, 2)}
+	want := []time.Time{date(2025, 12, 30), date(2025, 12, 31), date(2026, 1, 1)}
+
+	got := event.Days()
+	if len(got) != len(want) {
+		t.Fatalf("Days() = %v, want %v", got, want)
+	}
+	for i := range got {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("Days()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
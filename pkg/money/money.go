@@ -0,0 +1,296 @@
+// Package money holds monetary amounts as whole minor units so that pay is
+// never subject to binary floating point error.
+//
+// Every amount has two decimal places, matching the NUMERIC(15, 2) columns
+// it is stored in, and a minor unit is a hundredth of the currency unit
+// whatever the currency. Amounts are read from and written to PostgreSQL as
+// NUMERIC and serialised to JSON as decimal strings such as "1250000.50".
+//
+// Rounding: arithmetic that cannot be exact (MulDiv, Convert) rounds half
+// away from zero to the nearest minor unit. Input with more than two decimal
+// places is rejected rather than rounded, so an amount is never silently
+// changed on the way in.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DefaultCurrency is the ISO 4217 currency of amounts that do not carry one,
+// such as those read from a column or a request body.
+const DefaultCurrency = "IDR"
+
+// scale is the number of decimal places of an amount.
+const scale = 2
+
+var (
+	ErrInvalidAmount    = errors.New("money: invalid amount")
+	ErrTooPrecise       = errors.New("money: too many decimal places")
+	ErrOverflow         = errors.New("money: amount out of range")
+	errCurrencyMismatch = errors.New("money: currency mismatch")
+)
+
+var minorPerUnit = big.NewInt(100)
+
+// Money is an amount in minor units of a currency. The zero value is zero in
+// no particular currency, and adopts the currency of whatever it is added to.
+type Money struct {
+	Amount   int64  // minor units
+	Currency string // ISO 4217 code
+}
+
+// New returns minor units of currency.
+func New(minor int64, currency string) Money {
+	return Money{Amount: minor, Currency: currency}
+}
+
+// Parse reads a decimal string such as "-12.5" or "1000" in currency.
+func Parse(s, currency string) (Money, error) {
+	minor, err := parseDecimal(s, scale)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(minor, currency), nil
+}
+
+// parseDecimal reads a decimal string as an integer count of 10^-places.
+func parseDecimal(s string, places int) (int64, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" && frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return 0, ErrInvalidAmount
+	}
+	if len(frac) > places {
+		if strings.Trim(frac[places:], "0") != "" {
+			return 0, ErrTooPrecise
+		}
+		frac = frac[:places]
+	}
+	frac += strings.Repeat("0", places-len(frac))
+
+	n, ok := new(big.Int).SetString(whole+frac, 10)
+	if !ok {
+		return 0, ErrInvalidAmount
+	}
+	if negative {
+		n.Neg(n)
+	}
+	if !n.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return n.Int64(), nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the amount as a decimal with two places, without the
+// currency.
+func (m Money) String() string {
+	sign, minor := "", m.Amount
+	if minor < 0 {
+		sign = "-"
+	}
+	abs := new(big.Int).Abs(big.NewInt(minor))
+	whole, frac := new(big.Int).QuoRem(abs, minorPerUnit, new(big.Int))
+	return fmt.Sprintf("%s%s.%02d", sign, whole, frac.Int64())
+}
+
+func (m Money) IsZero() bool     { return m.Amount == 0 }
+func (m Money) IsPositive() bool { return m.Amount > 0 }
+func (m Money) IsNegative() bool { return m.Amount < 0 }
+
+// Add returns m + other. Adding amounts in different currencies is a
+// programming error and panics.
+func (m Money) Add(other Money) Money {
+	currency := m.sameCurrency(other)
+	sum := m.Amount + other.Amount
+	if (sum > m.Amount) != (other.Amount > 0) {
+		panic(ErrOverflow)
+	}
+	return New(sum, currency)
+}
+
+// Sub returns m - other. Subtracting amounts in different currencies is a
+// programming error and panics.
+func (m Money) Sub(other Money) Money {
+	return m.Add(New(-other.Amount, other.Currency))
+}
+
+// Mul returns m * n, which is always exact.
+func (m Money) Mul(n int64) Money {
+	return m.fromBig(new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(n)))
+}
+
+// MulDiv returns m * num / den, rounded half away from zero to the nearest
+// minor unit. The product is computed exactly before the single rounding.
+func (m Money) MulDiv(num, den int64) Money {
+	if den == 0 {
+		panic("money: division by zero")
+	}
+
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(num))
+	negative := product.Sign()*sign(den) < 0
+
+	// QuoRem truncates towards zero; step one further away from zero when
+	// the discarded remainder is at least half the divisor.
+	quotient, remainder := new(big.Int).QuoRem(product, big.NewInt(den), new(big.Int))
+	twice := remainder.Abs(remainder).Lsh(remainder, 1)
+	if twice.Cmp(new(big.Int).Abs(big.NewInt(den))) >= 0 {
+		if negative {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return m.fromBig(quotient)
+}
+
+// Cmp compares m with other, returning -1, 0 or +1. Comparing amounts in
+// different currencies is a programming error and panics.
+func (m Money) Cmp(other Money) int {
+	m.sameCurrency(other)
+	switch {
+	case m.Amount < other.Amount:
+		return -1
+	case m.Amount > other.Amount:
+		return 1
+	}
+	return 0
+}
+
+func sign(n int64) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+func (m Money) fromBig(minor *big.Int) Money {
+	if !minor.IsInt64() {
+		panic(ErrOverflow)
+	}
+	return New(minor.Int64(), m.Currency)
+}
+
+// sameCurrency returns the currency shared by m and other. A zero amount
+// without a currency takes the other's.
+func (m Money) sameCurrency(other Money) string {
+	switch {
+	case m.Currency == other.Currency:
+		return m.Currency
+	case m.Currency == "" && m.Amount == 0:
+		return other.Currency
+	case other.Currency == "" && other.Amount == 0:
+		return m.Currency
+	}
+	panic(fmt.Errorf("%w: %s and %s", errCurrencyMismatch, m.Currency, other.Currency))
+}
+
+// MarshalJSON encodes the amount as a decimal string.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON accepts a decimal string or, for older clients, a JSON
+// number. Either is read exactly, in DefaultCurrency.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s, err := decimalText(data)
+	if err != nil {
+		return err
+	}
+	parsed, err := Parse(s, DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// decimalText returns the decimal in a JSON string or number without going
+// through float64. Exponents are not accepted.
+func decimalText(data []byte) (string, error) {
+	var s string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return "", err
+		}
+	} else {
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return "", ErrInvalidAmount
+		}
+		s = n.String()
+	}
+
+	if strings.ContainsAny(s, "eE") {
+		return "", ErrInvalidAmount
+	}
+	return s, nil
+}
+
+// ScanNumeric implements pgtype.NumericScanner. The amount keeps the
+// currency already set, so a currency column can be scanned into Currency in
+// the same row, and is in DefaultCurrency otherwise.
+func (m *Money) ScanNumeric(v pgtype.Numeric) error {
+	minor, err := scanDecimal(v, scale)
+	if err != nil {
+		return err
+	}
+
+	currency := m.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	*m = New(minor, currency)
+	return nil
+}
+
+// scanDecimal converts a NUMERIC to an integer count of 10^-places.
+func scanDecimal(v pgtype.Numeric, places int) (int64, error) {
+	if !v.Valid {
+		return 0, errors.New("money: cannot scan NULL")
+	}
+	if v.NaN || v.InfinityModifier != pgtype.Finite {
+		return 0, ErrInvalidAmount
+	}
+
+	n := new(big.Int).Set(v.Int)
+	exp := int64(v.Exp) + int64(places)
+	if exp >= 0 {
+		n.Mul(n, new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil))
+	} else {
+		var remainder big.Int
+		n.QuoRem(n, new(big.Int).Exp(big.NewInt(10), big.NewInt(-exp), nil), &remainder)
+		if remainder.Sign() != 0 {
+			return 0, ErrTooPrecise
+		}
+	}
+	if !n.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return n.Int64(), nil
+}
+
+// NumericValue implements pgtype.NumericValuer.
+func (m Money) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(m.Amount), Exp: -scale, Valid: true}, nil
+}
@@ -0,0 +1,69 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMulDiv(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		num, den int64
+		want     int64
+	}{
+		{"exact", 1000, 3, 2, 1500},
+		{"rounds down below half", 14, 1, 10, 1},
+		{"rounds half up", 15, 1, 10, 2},
+		{"rounds up above half", 200, 1, 3, 67},
+		{"truncated third", 100, 1, 3, 33},
+		{"negative amount rounds half away from zero", -15, 1, 10, -2},
+		{"negative amount below half", -14, 1, 10, -1},
+		{"negative numerator", 5, -1, 2, -3},
+		{"negative denominator", 5, 1, -2, -3},
+		{"negative numerator and denominator", 5, -1, -2, 3},
+		{"zero amount", 0, 7, 3, 0},
+		{"zero numerator", 12345, 0, 7, 0},
+		{"product beyond int64 is exact", math.MaxInt64, 4, 4, math.MaxInt64},
+		{"proration of a monthly salary", 1_000_000_00, 17, 22, 772_727_27},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(tt.amount, "IDR").MulDiv(tt.num, tt.den)
+			if got.Amount != tt.want || got.Currency != "IDR" {
+				t.Errorf("MulDiv(%d, %d) of %d = %d %s, want %d IDR", tt.num, tt.den, tt.amount, got.Amount, got.Currency, tt.want)
+			}
+		})
+	}
+}
+
+func TestMulDivPanics(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		num, den int64
+		want     error // nil for a panic that is not an error
+	}{
+		{"overflows int64", math.MaxInt64, 2, 1, ErrOverflow},
+		{"overflows below int64", math.MinInt64, 2, 1, ErrOverflow},
+		{"rounds past int64", math.MaxInt64, 3, 2, ErrOverflow},
+		{"division by zero", 100, 1, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Fatalf("MulDiv(%d, %d) of %d did not panic", tt.num, tt.den, tt.amount)
+				}
+				if err, _ := r.(error); tt.want != nil && !errors.Is(err, tt.want) {
+					t.Errorf("MulDiv(%d, %d) of %d panicked with %v, want %v", tt.num, tt.den, tt.amount, r, tt.want)
+				}
+			}()
+			New(tt.amount, "IDR").MulDiv(tt.num, tt.den)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// rateScale is the number of decimal places of an exchange rate, matching the
+// NUMERIC(18, 8) column rates are stored in.
+const rateScale = 8
+
+var ratePerUnit = big.NewInt(100000000)
+
+// Rate is an exchange rate: one unit of a currency is worth Rate units of
+// another. It is held as an integer count of 10^-8.
+type Rate struct {
+	scaled int64
+}
+
+// ParseRate reads a decimal string such as "15750.5".
+func ParseRate(s string) (Rate, error) {
+	scaled, err := parseDecimal(s, rateScale)
+	if err != nil {
+		return Rate{}, err
+	}
+	return Rate{scaled: scaled}, nil
+}
+
+func (r Rate) IsPositive() bool { return r.scaled > 0 }
+
+// String formats the rate as a decimal with trailing zeros removed.
+func (r Rate) String() string {
+	sign := ""
+	if r.scaled < 0 {
+		sign = "-"
+	}
+	abs := new(big.Int).Abs(big.NewInt(r.scaled))
+	whole, frac := new(big.Int).QuoRem(abs, ratePerUnit, new(big.Int))
+	if frac.Sign() == 0 {
+		return sign + whole.String()
+	}
+
+	digits := fmt.Sprintf("%0*d", rateScale, frac.Int64())
+	for digits[len(digits)-1] == '0' {
+		digits = digits[:len(digits)-1]
+	}
+	return sign + whole.String() + "." + digits
+}
+
+// Convert returns m in currency at rate, rounded half away from zero to the
+// nearest minor unit.
+func (m Money) Convert(rate Rate, currency string) Money {
+	return New(m.Amount, currency).MulDiv(rate.scaled, ratePerUnit.Int64())
+}
+
+// ValidCurrency reports whether code looks like an ISO 4217 currency code.
+func ValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalJSON encodes the rate as a decimal string.
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON accepts a decimal string or a JSON number.
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	s, err := decimalText(data)
+	if err != nil {
+		return err
+	}
+	parsed, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// ScanNumeric implements pgtype.NumericScanner.
+func (r *Rate) ScanNumeric(v pgtype.Numeric) error {
+	scaled, err := scanDecimal(v, rateScale)
+	if err != nil {
+		return err
+	}
+	*r = Rate{scaled: scaled}
+	return nil
+}
+
+// NumericValue implements pgtype.NumericValuer.
+func (r Rate) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(r.scaled), Exp: -rateScale, Valid: true}, nil
+}
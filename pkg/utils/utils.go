@@ -1,15 +1,18 @@
 package utils
 
 import (
+	"net"
 	"net/http"
-	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// PasswordHashCost is the bcrypt cost passwords are hashed with.
+const PasswordHashCost = 14
+
 // Utility functions
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), PasswordHashCost)
 	return string(bytes), err
 }
 
@@ -18,17 +21,13 @@ func CheckPassword(password, hash string) bool {
 	return err == nil
 }
 
+// GetClientIP returns the address of the peer, without its port. Behind a
+// proxy, the middleware.RealIP middleware must have resolved the client's
+// address first; forwarding headers are not read here because any client
+// can set them.
 func GetClientIP(r *http.Request) string {
-	if ip := r.Header.Get("X-Forwarded-For"); ip != "" {
-		return ip
-	}
-	if ip := r.Header.Get("X-Real-IP"); ip != "" {
-		return ip
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
 	}
 	return r.RemoteAddr
 }
-
-func IsWeekend(date time.Time) bool {
-	weekday := date.Weekday()
-	return weekday == time.Saturday || weekday == time.Sunday
-}